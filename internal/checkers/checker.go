@@ -37,6 +37,10 @@ type CheckResult struct {
 
 type HostCheckResult struct {
 	Host string
+	// Family is set when the host was checked once per address family
+	Family string
+	// Address is set when the host was checked once per resolved address
+	Address string
 	CheckResult
 }
 
@@ -86,14 +90,14 @@ func (b *BaseChecker) checkHost(ctx context.Context, host string, checkFn func()
 	}
 
 	if err := ctx.Err(); err != nil {
-		result.Error = err
+		result.Error = classifyError(err)
 		result.Success = false
 		return result
 	}
 
 	metadata, err := checkFn()
 	if err != nil {
-		result.Error = err
+		result.Error = classifyError(err)
 		result.Success = false
 	}
 	result.Metadata = metadata
@@ -164,11 +168,18 @@ func (b *BaseChecker) parallelCheck(ctx context.Context, hosts []string, port st
 	return results
 }
 
+// CheckError is the error of a failed check, classified so rules and
+// metrics can tell a refused port from an expired certificate.
 type CheckError struct {
+	class    ErrorClass
 	err      error
 	metadata map[string]interface{}
 }
 
+func NewCheckError(class ErrorClass, err error) *CheckError {
+	return &CheckError{class: class, err: err}
+}
+
 func (e *CheckError) Error() string {
 	if e.err != nil {
 		return e.err.Error()
@@ -176,6 +187,14 @@ func (e *CheckError) Error() string {
 	return ""
 }
 
+func (e *CheckError) Unwrap() error {
+	return e.err
+}
+
+func (e *CheckError) Class() ErrorClass {
+	return e.class
+}
+
 func (e *CheckError) Metadata() map[string]interface{} {
 	return e.metadata
 }
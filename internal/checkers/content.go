@@ -0,0 +1,139 @@
+// Copyright (C) 2025 Jeff Rose
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package checkers
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/andybalholm/cascadia"
+	"golang.org/x/net/html"
+)
+
+const contentMaxBodyBytes = 10 << 20
+
+// contentWatch detects changes to a page by hashing its normalized body.
+// Dynamic regions can be stripped by regex or CSS selector before hashing.
+// The hash is compared with a pinned value if configured, otherwise with
+// the previous hash seen for the same host, so each change fails one check.
+type contentWatch struct {
+	ignorePatterns  []*regexp.Regexp
+	ignoreSelectors []cascadia.Sel
+	pinned          string
+
+	mu       sync.Mutex
+	previous map[string]string
+}
+
+// newContentWatch returns nil when content_hash is not enabled.
+func newContentWatch(opts Options) (*contentWatch, error) {
+	pinned := strings.ToLower(opts.String("content_hash_pinned", ""))
+	if !opts.Bool("content_hash", false) && pinned == "" {
+		return nil, nil
+	}
+
+	w := &contentWatch{
+		pinned:   pinned,
+		previous: make(map[string]string),
+	}
+
+	for _, pattern := range opts.StringSlice("content_ignore_regex") {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid content_ignore_regex %q: %w", pattern, err)
+		}
+		w.ignorePatterns = append(w.ignorePatterns, re)
+	}
+
+	for _, selector := range opts.StringSlice("content_ignore_selector") {
+		sel, err := cascadia.Parse(selector)
+		if err != nil {
+			return nil, fmt.Errorf("invalid content_ignore_selector %q: %w", selector, err)
+		}
+		w.ignoreSelectors = append(w.ignoreSelectors, sel)
+	}
+	return w, nil
+}
+
+// verify hashes the body and compares it with the pinned hash, or with the
+// previous hash of host. The first observation of a host passes.
+func (w *contentWatch) verify(host string, body io.Reader, metadata map[string]interface{}) error {
+	data, err := io.ReadAll(io.LimitReader(body, contentMaxBodyBytes))
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+
+	normalized, err := w.normalize(data)
+	if err != nil {
+		return err
+	}
+	sum := sha256.Sum256(normalized)
+	hash := hex.EncodeToString(sum[:])
+	metadata["content_hash"] = hash
+
+	if w.pinned != "" {
+		metadata["content_changed"] = hash != w.pinned
+		if hash != w.pinned {
+			return NewCheckError(ErrorAssertion, fmt.Errorf("content hash %s does not match pinned %s", hash, w.pinned))
+		}
+		return nil
+	}
+
+	w.mu.Lock()
+	previous, seen := w.previous[host]
+	w.previous[host] = hash
+	w.mu.Unlock()
+
+	changed := seen && previous != hash
+	metadata["content_changed"] = changed
+	if changed {
+		metadata["content_previous_hash"] = previous
+		return NewCheckError(ErrorAssertion, fmt.Errorf("content changed: hash %s, previously %s", hash, previous))
+	}
+	return nil
+}
+
+func (w *contentWatch) normalize(data []byte) ([]byte, error) {
+	if len(w.ignoreSelectors) > 0 {
+		doc, err := html.Parse(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse html: %w", err)
+		}
+		for _, sel := range w.ignoreSelectors {
+			for _, node := range cascadia.QueryAll(doc, sel) {
+				if node.Parent != nil {
+					node.Parent.RemoveChild(node)
+				}
+			}
+		}
+		var buf bytes.Buffer
+		if err := html.Render(&buf, doc); err != nil {
+			return nil, fmt.Errorf("failed to render html: %w", err)
+		}
+		data = buf.Bytes()
+	}
+
+	for _, re := range w.ignorePatterns {
+		data = re.ReplaceAll(data, nil)
+	}
+	return bytes.Join(bytes.Fields(data), []byte(" ")), nil
+}
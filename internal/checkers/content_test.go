@@ -0,0 +1,65 @@
+// Copyright (C) 2025 Jeff Rose
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package checkers
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestContentWatchReportsEachChangeOnce(t *testing.T) {
+	w, err := newContentWatch(Options{"content_hash": true, "content_ignore_regex": []string{`\d{2}:\d{2}`}})
+	if err != nil {
+		t.Fatalf("newContentWatch: %v", err)
+	}
+
+	rounds := []struct {
+		body    string
+		changed bool
+	}{
+		{"<p>Welcome</p> 10:00", false},
+		{"<p>Welcome</p> 10:05", false},
+		{"<p>Hacked</p>", true},
+		{"<p>Hacked</p>", false},
+		{"<p>Welcome</p> 11:00", true},
+		{"<p>Welcome</p>  11:05", false},
+	}
+	for i, round := range rounds {
+		metadata := make(map[string]interface{})
+		err := w.verify("web1:80", strings.NewReader(round.body), metadata)
+		if (err != nil) != round.changed || metadata["content_changed"] != round.changed {
+			t.Errorf("round %d: error = %v, content_changed = %v, want changed %v", i, err, metadata["content_changed"], round.changed)
+		}
+	}
+}
+
+func TestContentWatchPinned(t *testing.T) {
+	// sha256 of "hello"
+	w, err := newContentWatch(Options{"content_hash_pinned": "2CF24DBA5FB0A30E26E83B2AC5B9E29E1B161E5C1FA7425E73043362938B9824"})
+	if err != nil {
+		t.Fatalf("newContentWatch: %v", err)
+	}
+	for _, body := range []string{"hello", " hello\n"} {
+		if err := w.verify("web1:80", strings.NewReader(body), map[string]interface{}{}); err != nil {
+			t.Errorf("verify(%q) = %v, want nil", body, err)
+		}
+	}
+	for i := 0; i < 2; i++ {
+		if err := w.verify("web1:80", strings.NewReader("goodbye"), map[string]interface{}{}); err == nil {
+			t.Errorf("verify(goodbye) round %d = nil, want error while the hash differs", i)
+		}
+	}
+}
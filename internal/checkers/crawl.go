@@ -0,0 +1,275 @@
+// Copyright (C) 2025 Jeff Rose
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package checkers
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/html"
+)
+
+const (
+	crawlMinTimeout     = 5 * time.Second
+	crawlMaxTimeout     = 5 * time.Minute
+	crawlDefaultTimeout = 60 * time.Second
+
+	crawlDefaultDepth       = 2
+	crawlDefaultMaxPages    = 100
+	crawlDefaultConcurrency = 4
+	crawlMaxBodyBytes       = 5 << 20
+	crawlMaxReportedLinks   = 10
+)
+
+// linkAttributes lists the elements and attributes that reference other
+// resources on a page.
+var linkAttributes = map[string]string{
+	"a":      "href",
+	"link":   "href",
+	"img":    "src",
+	"script": "src",
+	"iframe": "src",
+}
+
+// CrawlChecker starts at a page on each host and follows same-origin links,
+// failing when any of them is broken.
+type CrawlChecker struct {
+	BaseChecker
+	scheme      string
+	startPath   string
+	maxDepth    int
+	maxPages    int
+	concurrency int
+	client      *http.Client
+}
+
+func NewCrawlChecker() *CrawlChecker {
+	return &CrawlChecker{
+		BaseChecker: NewBaseChecker(TimeoutBounds{
+			Min:     crawlMinTimeout,
+			Max:     crawlMaxTimeout,
+			Default: crawlDefaultTimeout,
+		}),
+		scheme:      "http",
+		startPath:   "/",
+		maxDepth:    crawlDefaultDepth,
+		maxPages:    crawlDefaultMaxPages,
+		concurrency: crawlDefaultConcurrency,
+		client:      NewHTTPChecker().client,
+	}
+}
+
+func (c *CrawlChecker) Protocol() Protocol {
+	return "CRAWL"
+}
+
+// Configure reuses the HTTP or HTTPS checker's client so crawls honor the
+// same request settings (verify_cert, http_version) as single page checks.
+func (c *CrawlChecker) Configure(opts Options) error {
+	c.scheme = strings.ToLower(opts.String("scheme", "http"))
+	switch c.scheme {
+	case "http":
+		checker := NewHTTPChecker()
+		if err := checker.Configure(opts); err != nil {
+			return err
+		}
+		c.client = checker.client
+	case "https":
+		checker := NewHTTPSChecker()
+		if err := checker.Configure(opts); err != nil {
+			return err
+		}
+		c.client = checker.client
+	default:
+		return fmt.Errorf("unsupported scheme %q", c.scheme)
+	}
+
+	c.startPath = opts.String("start_path", "/")
+	var err error
+	if c.maxDepth, err = opts.Int("max_depth", crawlDefaultDepth); err != nil {
+		return err
+	}
+	if c.maxPages, err = opts.Int("max_pages", crawlDefaultMaxPages); err != nil {
+		return err
+	}
+	if c.concurrency, err = opts.Int("concurrency", crawlDefaultConcurrency); err != nil {
+		return err
+	}
+	if c.concurrency < 1 {
+		c.concurrency = 1
+	}
+	return nil
+}
+
+func (c *CrawlChecker) Check(ctx context.Context, hosts []string, port string) []HostCheckResult {
+	return c.BaseChecker.Check(ctx, hosts, port, c.checkCrawl)
+}
+
+type crawlResult struct {
+	url    string
+	broken string
+	links  []string
+}
+
+func (c *CrawlChecker) checkCrawl(ctx context.Context, host string, port string) (map[string]interface{}, error) {
+	start, err := url.Parse(fmt.Sprintf("%s://%s%s", c.scheme, net.JoinHostPort(host, port), c.startPath))
+	if err != nil {
+		return nil, fmt.Errorf("invalid start url: %w", err)
+	}
+
+	seen := map[string]bool{start.String(): true}
+	level := []string{start.String()}
+	var broken []string
+	checked := 0
+
+	for depth := 0; len(level) > 0 && ctx.Err() == nil; depth++ {
+		results := c.fetchLevel(ctx, level, depth < c.maxDepth)
+		checked += len(level)
+
+		var next []string
+		for _, result := range results {
+			if result.broken != "" {
+				broken = append(broken, fmt.Sprintf("%s (%s)", result.url, result.broken))
+			}
+			for _, link := range result.links {
+				if seen[link] || checked+len(next) >= c.maxPages || !sameOrigin(start, link) {
+					continue
+				}
+				seen[link] = true
+				next = append(next, link)
+			}
+		}
+		level = next
+	}
+
+	metadata := map[string]interface{}{
+		"pages_checked": checked,
+		"broken_count":  len(broken),
+		"broken_links":  broken,
+	}
+	if len(broken) > 0 {
+		reported := broken
+		if len(reported) > crawlMaxReportedLinks {
+			reported = append(reported[:crawlMaxReportedLinks:crawlMaxReportedLinks], fmt.Sprintf("and %d more", len(broken)-crawlMaxReportedLinks))
+		}
+		return metadata, NewCheckError(ErrorAssertion, fmt.Errorf("%d broken links: %s", len(broken), strings.Join(reported, ", ")))
+	}
+	if err := ctx.Err(); err != nil {
+		return metadata, fmt.Errorf("crawl did not finish: %w", err)
+	}
+	return metadata, nil
+}
+
+// fetchLevel fetches one depth of the crawl with bounded concurrency. Links
+// are only extracted when the next depth will be crawled.
+func (c *CrawlChecker) fetchLevel(ctx context.Context, urls []string, extractLinks bool) []crawlResult {
+	results := make([]crawlResult, len(urls))
+	sem := make(chan struct{}, c.concurrency)
+	var wg sync.WaitGroup
+
+	for i, u := range urls {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, u string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			results[i] = c.fetch(ctx, u, extractLinks)
+		}(i, u)
+	}
+
+	wg.Wait()
+	return results
+}
+
+func (c *CrawlChecker) fetch(ctx context.Context, pageURL string, extractLinks bool) crawlResult {
+	result := crawlResult{url: pageURL}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, pageURL, nil)
+	if err != nil {
+		result.broken = err.Error()
+		return result
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			result.broken = "timeout"
+		} else {
+			result.broken = err.Error()
+		}
+		return result
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		result.broken = fmt.Sprint(resp.StatusCode)
+		return result
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if !extractLinks || mediaType != "text/html" {
+		_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, crawlMaxBodyBytes))
+		return result
+	}
+
+	result.links = extractPageLinks(resp.Request.URL, io.LimitReader(resp.Body, crawlMaxBodyBytes))
+	return result
+}
+
+func extractPageLinks(base *url.URL, r io.Reader) []string {
+	var links []string
+	tokenizer := html.NewTokenizer(r)
+	for {
+		switch tokenizer.Next() {
+		case html.ErrorToken:
+			return links
+		case html.StartTagToken, html.SelfClosingTagToken:
+			token := tokenizer.Token()
+			attr, ok := linkAttributes[token.Data]
+			if !ok {
+				continue
+			}
+			for _, a := range token.Attr {
+				if a.Key != attr {
+					continue
+				}
+				ref, err := base.Parse(strings.TrimSpace(a.Val))
+				if err != nil || (ref.Scheme != "http" && ref.Scheme != "https") {
+					continue
+				}
+				ref.Fragment = ""
+				links = append(links, ref.String())
+			}
+		}
+	}
+}
+
+func sameOrigin(origin *url.URL, link string) bool {
+	u, err := url.Parse(link)
+	return err == nil && u.Scheme == origin.Scheme && u.Host == origin.Host
+}
+
+func init() {
+	RegisterChecker("CRAWL", func() Checker { return NewCrawlChecker() })
+}
@@ -0,0 +1,25 @@
+// Copyright (C) 2025 Jeff Rose
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package checkers
+
+import "syscall"
+
+const supportsBindToDevice = true
+
+// bindToDevice sets SO_BINDTODEVICE, which needs CAP_NET_RAW.
+func bindToDevice(fd uintptr, iface string) error {
+	return syscall.BindToDevice(int(fd), iface)
+}
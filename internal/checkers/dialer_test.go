@@ -0,0 +1,57 @@
+// Copyright (C) 2025 Jeff Rose
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package checkers
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestNewHTTPTransportEnvironmentProxy(t *testing.T) {
+	t.Setenv("HTTP_PROXY", "http://env-proxy.example.com:3128")
+
+	tests := []struct {
+		name    string
+		opts    Options
+		wantEnv bool
+	}{
+		{name: "unset", opts: Options{}, wantEnv: true},
+		{name: "direct", opts: Options{"proxy": "direct"}},
+		{name: "direct pinned", opts: Options{"proxy": "direct", "resolve_all": true}},
+		{name: "configured", opts: Options{"proxy": "http://bastion.example.com:3128"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, err := newDialer(tt.opts)
+			if err != nil {
+				t.Fatalf("newDialer: %v", err)
+			}
+			transport := newHTTPTransport(d)
+			if got := transport.Proxy != nil; got != tt.wantEnv {
+				t.Errorf("environment proxy used = %v, want %v", got, tt.wantEnv)
+			}
+			if transport.Proxy == nil {
+				return
+			}
+			req, _ := http.NewRequest(http.MethodGet, "http://target.example.com/", nil)
+			proxyURL, err := transport.Proxy(req)
+			if err != nil || proxyURL == nil || proxyURL.Host != "env-proxy.example.com:3128" {
+				t.Errorf("Proxy() = %v, %v, want env-proxy.example.com:3128", proxyURL, err)
+			}
+		})
+	}
+}
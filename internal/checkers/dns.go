@@ -32,6 +32,7 @@ const (
 type DNSChecker struct {
 	BaseChecker
 	resolver *net.Resolver
+	family   string
 	mu       sync.RWMutex
 }
 
@@ -50,6 +51,28 @@ func (c *DNSChecker) Protocol() Protocol {
 	return "DNS"
 }
 
+// Configure sends lookups from the configured source. DNS is never proxied.
+// The address family selects the records looked up (A, AAAA or both), not
+// the transport used to reach the nameserver.
+func (c *DNSChecker) Configure(opts Options) error {
+	family, err := addressFamily(opts)
+	if err != nil {
+		return err
+	}
+	dialer, err := newSourceDialer(opts, "")
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.family = family
+	if _, ok := dialer.(*sourceDialer); ok {
+		c.resolver = &net.Resolver{PreferGo: true, Dial: dialer.DialContext}
+	}
+	return nil
+}
+
 func (c *DNSChecker) Check(ctx context.Context, hosts []string, port string) []HostCheckResult {
 	return c.BaseChecker.Check(ctx, hosts, port, c.checkDNS)
 }
@@ -58,13 +81,17 @@ func (c *DNSChecker) checkDNS(ctx context.Context, host string, port string) (ma
 	lookupCtx, cancel := context.WithTimeout(ctx, c.GetTimeout())
 	defer cancel()
 
-	ips, err := c.resolver.LookupIP(lookupCtx, "ip4", host)
+	c.mu.RLock()
+	resolver, family := c.resolver, c.family
+	c.mu.RUnlock()
+
+	ips, err := resolver.LookupIP(lookupCtx, lookupNetwork(family), host)
 	if err != nil {
 		return nil, fmt.Errorf("dns lookup failed: %w", err)
 	}
 
 	if len(ips) == 0 {
-		return nil, fmt.Errorf("no IP addresses found for host")
+		return nil, NewCheckError(ErrorDNS, fmt.Errorf("no IP addresses found for host"))
 	}
 
 	return map[string]interface{}{
@@ -0,0 +1,235 @@
+// Copyright (C) 2025 Jeff Rose
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package checkers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	dockerMinTimeout     = 1 * time.Second
+	dockerMaxTimeout     = 20 * time.Second
+	dockerDefaultTimeout = 5 * time.Second
+
+	dockerDefaultSocket = "/var/run/docker.sock"
+)
+
+// ContainerStatus is the state of a single container as reported by Docker.
+type ContainerStatus struct {
+	Name         string
+	State        string
+	Health       string
+	RestartCount int
+}
+
+// DockerChecker inspects containers through the Docker Engine API on a unix
+// socket. Containers are selected by name and/or label.
+type DockerChecker struct {
+	BaseChecker
+	socket     string
+	containers []string
+	labels     []string
+	client     *http.Client
+}
+
+func NewDockerChecker() *DockerChecker {
+	c := &DockerChecker{
+		BaseChecker: NewBaseChecker(TimeoutBounds{
+			Min:     dockerMinTimeout,
+			Max:     dockerMaxTimeout,
+			Default: dockerDefaultTimeout,
+		}),
+		socket: dockerDefaultSocket,
+	}
+	c.client = &http.Client{
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				var dialer net.Dialer
+				return dialer.DialContext(ctx, "unix", c.socket)
+			},
+		},
+	}
+	return c
+}
+
+func (c *DockerChecker) Protocol() Protocol {
+	return "DOCKER"
+}
+
+func (c *DockerChecker) Configure(opts Options) error {
+	c.socket = opts.String("socket", dockerDefaultSocket)
+	c.containers = opts.StringSlice("containers")
+	c.labels = opts.StringSlice("labels")
+	if len(c.containers) == 0 && len(c.labels) == 0 {
+		return errors.New("containers or labels must be specified")
+	}
+	return nil
+}
+
+func (c *DockerChecker) Check(ctx context.Context, hosts []string, port string) []HostCheckResult {
+	return c.BaseChecker.Check(ctx, hosts, port, func(ctx context.Context, host string, _ string) (map[string]interface{}, error) {
+		return localCheck(ctx, host, func() (map[string]interface{}, error) {
+			return c.checkDocker(ctx)
+		})
+	})
+}
+
+type dockerContainerSummary struct {
+	ID    string   `json:"Id"`
+	Names []string `json:"Names"`
+}
+
+type dockerContainerInspect struct {
+	Name         string `json:"Name"`
+	RestartCount int    `json:"RestartCount"`
+	State        struct {
+		Status string `json:"Status"`
+		Health *struct {
+			Status string `json:"Status"`
+		} `json:"Health"`
+	} `json:"State"`
+}
+
+func (c *DockerChecker) checkDocker(ctx context.Context) (map[string]interface{}, error) {
+	summaries, err := c.listContainers(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var statuses []ContainerStatus
+	var problems []string
+	found := make(map[string]bool)
+
+	for _, summary := range summaries {
+		name := containerName(summary.Names)
+		if len(c.containers) > 0 && !slices.Contains(c.containers, name) {
+			continue
+		}
+		found[name] = true
+
+		var inspect dockerContainerInspect
+		if err := c.get(ctx, "/containers/"+summary.ID+"/json", &inspect); err != nil {
+			return nil, err
+		}
+
+		status := ContainerStatus{
+			Name:         name,
+			State:        inspect.State.Status,
+			RestartCount: inspect.RestartCount,
+		}
+		if inspect.State.Health != nil {
+			status.Health = inspect.State.Health.Status
+		}
+		statuses = append(statuses, status)
+
+		switch {
+		case status.State != "running":
+			problems = append(problems, fmt.Sprintf("%s is %s", name, status.State))
+		case status.Health == "unhealthy":
+			problems = append(problems, name+" is unhealthy")
+		}
+	}
+
+	for _, name := range c.containers {
+		if !found[name] {
+			problems = append(problems, name+" not found")
+		}
+	}
+	if len(c.containers) == 0 && len(statuses) == 0 {
+		problems = append(problems, "no containers match labels "+strings.Join(c.labels, ","))
+	}
+
+	running, unhealthy, restarts := 0, 0, 0
+	for _, status := range statuses {
+		if status.State == "running" {
+			running++
+		}
+		if status.Health == "unhealthy" {
+			unhealthy++
+		}
+		restarts += status.RestartCount
+	}
+
+	metadata := map[string]interface{}{
+		"containers":           statuses,
+		"containers_running":   running,
+		"containers_unhealthy": unhealthy,
+		"restart_count":        restarts,
+	}
+	if len(problems) > 0 {
+		return metadata, NewCheckError(ErrorAssertion, errors.New(strings.Join(problems, ", ")))
+	}
+	return metadata, nil
+}
+
+func (c *DockerChecker) listContainers(ctx context.Context) ([]dockerContainerSummary, error) {
+	query := url.Values{"all": {"true"}}
+	if len(c.labels) > 0 {
+		filters, err := json.Marshal(map[string][]string{"label": c.labels})
+		if err != nil {
+			return nil, err
+		}
+		query.Set("filters", string(filters))
+	}
+
+	var summaries []dockerContainerSummary
+	if err := c.get(ctx, "/containers/json?"+query.Encode(), &summaries); err != nil {
+		return nil, err
+	}
+	return summaries, nil
+}
+
+func (c *DockerChecker) get(ctx context.Context, path string, out interface{}) error {
+	// The host part is ignored, requests always go to the socket.
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://docker"+path, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("docker api request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return NewCheckError(ErrorHTTPStatus, fmt.Errorf("docker api status error: %d", resp.StatusCode))
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("invalid docker api response: %w", err)
+	}
+	return nil
+}
+
+func containerName(names []string) string {
+	if len(names) == 0 {
+		return ""
+	}
+	return strings.TrimPrefix(names[0], "/")
+}
+
+func init() {
+	RegisterChecker("DOCKER", func() Checker { return NewDockerChecker() })
+}
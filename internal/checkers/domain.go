@@ -0,0 +1,183 @@
+// Copyright (C) 2025 Jeff Rose
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package checkers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	domainMinTimeout     = 2 * time.Second
+	domainMaxTimeout     = 30 * time.Second
+	domainDefaultTimeout = 10 * time.Second
+
+	rdapDefaultBaseURL = "https://rdap.org"
+)
+
+type DomainInfo struct {
+	ExpiresAt time.Time
+	Registrar string
+	Status    []string
+}
+
+// DomainChecker looks up the registration of each host, treated as a domain
+// name, over RDAP.
+type DomainChecker struct {
+	BaseChecker
+	baseURL string
+	client  *http.Client
+}
+
+func NewDomainChecker() *DomainChecker {
+	return &DomainChecker{
+		BaseChecker: NewBaseChecker(TimeoutBounds{
+			Min:     domainMinTimeout,
+			Max:     domainMaxTimeout,
+			Default: domainDefaultTimeout,
+		}),
+		baseURL: rdapDefaultBaseURL,
+		client:  &http.Client{Transport: newHTTPTransport(&net.Dialer{})},
+	}
+}
+
+func (c *DomainChecker) Protocol() Protocol {
+	return "DOMAIN"
+}
+
+func (c *DomainChecker) Configure(opts Options) error {
+	c.baseURL = strings.TrimSuffix(opts.String("rdap_url", rdapDefaultBaseURL), "/")
+	if _, err := url.Parse(c.baseURL); err != nil {
+		return fmt.Errorf("invalid rdap_url: %w", err)
+	}
+
+	dialer, err := newDialer(opts)
+	if err != nil {
+		return err
+	}
+	c.client.Transport = newHTTPTransport(dialer)
+	return nil
+}
+
+func (c *DomainChecker) Check(ctx context.Context, hosts []string, port string) []HostCheckResult {
+	return c.BaseChecker.Check(ctx, hosts, port, c.checkDomain)
+}
+
+type rdapDomain struct {
+	Status []string `json:"status"`
+	Events []struct {
+		Action string `json:"eventAction"`
+		Date   string `json:"eventDate"`
+	} `json:"events"`
+	Entities []struct {
+		Roles      []string        `json:"roles"`
+		VCardArray json.RawMessage `json:"vcardArray"`
+	} `json:"entities"`
+}
+
+func (c *DomainChecker) checkDomain(ctx context.Context, host string, _ string) (map[string]interface{}, error) {
+	domain := strings.TrimSuffix(strings.ToLower(host), ".")
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/domain/"+url.PathEscape(domain), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/rdap+json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("rdap request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, NewCheckError(ErrorAssertion, fmt.Errorf("domain %s is not registered", domain))
+	}
+	if resp.StatusCode >= 400 {
+		return nil, NewCheckError(ErrorHTTPStatus, fmt.Errorf("rdap status error: %d", resp.StatusCode))
+	}
+
+	var record rdapDomain
+	if err := json.NewDecoder(resp.Body).Decode(&record); err != nil {
+		return nil, fmt.Errorf("invalid rdap response: %w", err)
+	}
+
+	info := &DomainInfo{Status: record.Status}
+	for _, event := range record.Events {
+		if event.Action != "expiration" {
+			continue
+		}
+		if info.ExpiresAt, err = time.Parse(time.RFC3339, event.Date); err != nil {
+			return nil, fmt.Errorf("invalid expiration date %q", event.Date)
+		}
+	}
+	for _, entity := range record.Entities {
+		if slices.Contains(entity.Roles, "registrar") {
+			info.Registrar = vcardName(entity.VCardArray)
+		}
+	}
+
+	metadata := map[string]interface{}{
+		"domain_info": info,
+	}
+	if info.ExpiresAt.IsZero() {
+		return metadata, errors.New("rdap response has no expiration date")
+	}
+	metadata["domain_days_remaining"] = time.Until(info.ExpiresAt).Hours() / 24
+
+	if time.Now().After(info.ExpiresAt) {
+		return metadata, NewCheckError(ErrorAssertion, fmt.Errorf("domain %s expired on %s", domain, info.ExpiresAt.Format(time.DateOnly)))
+	}
+	for _, status := range info.Status {
+		if strings.Contains(status, "redemption") || strings.Contains(status, "pending delete") {
+			return metadata, NewCheckError(ErrorAssertion, fmt.Errorf("domain %s has status %q", domain, status))
+		}
+	}
+	return metadata, nil
+}
+
+// vcardName returns the "fn" property of a jCard: ["vcard", [["fn", {}, "text", "Name"], ...]]
+func vcardName(raw json.RawMessage) string {
+	var card []interface{}
+	if err := json.Unmarshal(raw, &card); err != nil || len(card) < 2 {
+		return ""
+	}
+	properties, ok := card[1].([]interface{})
+	if !ok {
+		return ""
+	}
+	for _, p := range properties {
+		property, ok := p.([]interface{})
+		if !ok || len(property) < 4 || property[0] != "fn" {
+			continue
+		}
+		if name, ok := property[3].(string); ok {
+			return name
+		}
+	}
+	return ""
+}
+
+func init() {
+	RegisterChecker("DOMAIN", func() Checker { return NewDomainChecker() })
+}
@@ -0,0 +1,264 @@
+// Copyright (C) 2025 Jeff Rose
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package checkers
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"net/smtp"
+	"time"
+)
+
+const (
+	emailMinTimeout     = 10 * time.Second
+	emailMaxTimeout     = 5 * time.Minute
+	emailDefaultTimeout = 2 * time.Minute
+
+	emailTokenHeader         = "X-CheckMate-Token"
+	emailDefaultIMAPPort     = "993"
+	emailDefaultMailbox      = "INBOX"
+	emailDefaultPollInterval = 5 * time.Second
+)
+
+// EmailChecker sends a tagged message through each SMTP host and waits for it
+// to show up in an IMAP mailbox, reporting the end-to-end delivery time.
+type EmailChecker struct {
+	BaseChecker
+	from         string
+	to           string
+	smtpUsername string
+	smtpPassword string
+	startTLS     bool
+	imapHost     string
+	imapPort     string
+	imapTLS      bool
+	imapUsername string
+	imapPassword string
+	mailbox      string
+	pollInterval time.Duration
+	dialer       Dialer
+}
+
+func NewEmailChecker() *EmailChecker {
+	return &EmailChecker{
+		BaseChecker: NewBaseChecker(TimeoutBounds{
+			Min:     emailMinTimeout,
+			Max:     emailMaxTimeout,
+			Default: emailDefaultTimeout,
+		}),
+		imapPort:     emailDefaultIMAPPort,
+		imapTLS:      true,
+		mailbox:      emailDefaultMailbox,
+		pollInterval: emailDefaultPollInterval,
+		dialer:       &net.Dialer{},
+	}
+}
+
+func (c *EmailChecker) Protocol() Protocol {
+	return "EMAIL"
+}
+
+func (c *EmailChecker) Configure(opts Options) error {
+	c.from = opts.String("from", "")
+	c.to = opts.String("to", "")
+	c.smtpUsername = opts.String("smtp_username", "")
+	c.smtpPassword = opts.String("smtp_password", "")
+	c.startTLS = opts.Bool("starttls", false)
+	c.imapHost = opts.String("imap_host", "")
+	c.imapPort = opts.String("imap_port", emailDefaultIMAPPort)
+	c.imapTLS = opts.Bool("imap_tls", true)
+	c.imapUsername = opts.String("imap_username", "")
+	c.imapPassword = opts.String("imap_password", "")
+	c.mailbox = opts.String("imap_mailbox", emailDefaultMailbox)
+
+	pollInterval, err := opts.Duration("poll_interval", emailDefaultPollInterval)
+	if err != nil {
+		return err
+	}
+	c.pollInterval = pollInterval
+
+	if c.dialer, err = newDialer(opts); err != nil {
+		return err
+	}
+
+	if c.from == "" || c.to == "" {
+		return errors.New("from and to must be specified")
+	}
+	if c.imapHost == "" || c.imapUsername == "" {
+		return errors.New("imap_host and imap_username must be specified")
+	}
+	return nil
+}
+
+func (c *EmailChecker) Check(ctx context.Context, hosts []string, port string) []HostCheckResult {
+	return c.BaseChecker.Check(ctx, hosts, port, c.checkEmail)
+}
+
+func (c *EmailChecker) checkEmail(ctx context.Context, host string, port string) (map[string]interface{}, error) {
+	token, err := newEmailToken()
+	if err != nil {
+		return nil, err
+	}
+
+	sentAt := time.Now()
+	if err := c.sendProbe(ctx, host, port, token); err != nil {
+		return nil, err
+	}
+
+	if err := c.awaitProbe(ctx, token); err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"delivery_time": time.Since(sentAt),
+		"token":         token,
+	}, nil
+}
+
+func (c *EmailChecker) sendProbe(ctx context.Context, host string, port string, token string) error {
+	conn, err := c.dialer.DialContext(ctx, "tcp", net.JoinHostPort(host, port))
+	if err != nil {
+		return fmt.Errorf("smtp connection failed: %w", err)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	client, err := smtp.NewClient(conn, host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("smtp connection failed: %w", err)
+	}
+	defer client.Close()
+
+	if err := client.Hello("checkmate.monitor"); err != nil {
+		return fmt.Errorf("smtp hello failed: %w", err)
+	}
+	if c.startTLS {
+		if err := client.StartTLS(&tls.Config{ServerName: host}); err != nil {
+			return fmt.Errorf("smtp starttls failed: %w", err)
+		}
+	}
+	if c.smtpUsername != "" {
+		if err := client.Auth(smtp.PlainAuth("", c.smtpUsername, c.smtpPassword, host)); err != nil {
+			return fmt.Errorf("smtp auth failed: %w", err)
+		}
+	}
+
+	if err := client.Mail(c.from); err != nil {
+		return fmt.Errorf("smtp mail from failed: %w", err)
+	}
+	if err := client.Rcpt(c.to); err != nil {
+		return fmt.Errorf("smtp rcpt to failed: %w", err)
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("smtp data failed: %w", err)
+	}
+	message := fmt.Sprintf("From: %s\r\nTo: %s\r\nSubject: CheckMate delivery probe %s\r\n%s: %s\r\nDate: %s\r\n\r\nSent via %s by CheckMate.\r\n",
+		c.from, c.to, token, emailTokenHeader, token, time.Now().Format(time.RFC1123Z), host)
+	if _, err := w.Write([]byte(message)); err != nil {
+		return fmt.Errorf("smtp write failed: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("smtp message rejected: %w", err)
+	}
+
+	return client.Quit()
+}
+
+func (c *EmailChecker) awaitProbe(ctx context.Context, token string) error {
+	client, err := c.dialIMAP(ctx)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	if err := client.Login(c.imapUsername, c.imapPassword); err != nil {
+		return fmt.Errorf("imap login failed: %w", err)
+	}
+
+	ticker := time.NewTicker(c.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		// Re-selecting the mailbox makes servers report newly delivered mail.
+		if err := client.Select(c.mailbox); err != nil {
+			return fmt.Errorf("imap select failed: %w", err)
+		}
+
+		uids, err := client.SearchHeader(emailTokenHeader, token)
+		if err != nil {
+			return fmt.Errorf("imap search failed: %w", err)
+		}
+		if len(uids) > 0 {
+			if err := client.Delete(uids); err != nil {
+				return fmt.Errorf("imap delete failed: %w", err)
+			}
+			_ = client.Logout()
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("probe message not delivered: %w", ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
+func (c *EmailChecker) dialIMAP(ctx context.Context) (*imapClient, error) {
+	address := net.JoinHostPort(c.imapHost, c.imapPort)
+
+	var conn net.Conn
+	var err error
+	if c.imapTLS {
+		conn, err = dialTLS(ctx, c.dialer, "tcp", address, &tls.Config{ServerName: c.imapHost})
+	} else {
+		conn, err = c.dialer.DialContext(ctx, "tcp", address)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("imap connection failed: %w", err)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	client, err := newIMAPClient(conn)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return client, nil
+}
+
+func newEmailToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate probe token: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func init() {
+	RegisterChecker("EMAIL", func() Checker { return NewEmailChecker() })
+}
@@ -0,0 +1,129 @@
+// Copyright (C) 2025 Jeff Rose
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package checkers
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"net"
+	"strings"
+	"syscall"
+)
+
+// ErrorClass names the kind of failure behind a check error.
+type ErrorClass string
+
+const (
+	ErrorDNS               ErrorClass = "dns_failure"        // The name could not be resolved
+	ErrorConnectionRefused ErrorClass = "connection_refused" // Nothing listens on the port
+	ErrorConnectionFailed  ErrorClass = "connection_failed"  // Unreachable, reset or otherwise broken connection
+	ErrorTimeout           ErrorClass = "timeout"            // No answer within the timeout
+	ErrorTLS               ErrorClass = "tls_error"          // Handshake or certificate verification failed
+	ErrorHTTPStatus        ErrorClass = "http_status"        // An unexpected HTTP (or SIP) status code
+	ErrorAssertion         ErrorClass = "assertion_failed"   // The service answered, but not as expected
+	ErrorProtocol          ErrorClass = "protocol_error"     // A malformed or unexpected protocol exchange
+)
+
+var errorClasses = map[ErrorClass]bool{
+	ErrorDNS:               true,
+	ErrorConnectionRefused: true,
+	ErrorConnectionFailed:  true,
+	ErrorTimeout:           true,
+	ErrorTLS:               true,
+	ErrorHTTPStatus:        true,
+	ErrorAssertion:         true,
+	ErrorProtocol:          true,
+}
+
+func (c ErrorClass) IsValid() bool {
+	return errorClasses[c]
+}
+
+// ErrorClassOf returns the class of a check error, empty for nil. Errors
+// that were not classified by their checker are classified by their cause.
+func ErrorClassOf(err error) ErrorClass {
+	if err == nil {
+		return ""
+	}
+
+	var checkErr *CheckError
+	if errors.As(err, &checkErr) && checkErr.class != "" {
+		return checkErr.class
+	}
+
+	var targetErr *proxyTargetError
+	if errors.As(err, &targetErr) {
+		if targetErr.state == PortClosed {
+			return ErrorConnectionRefused
+		}
+		return ErrorConnectionFailed
+	}
+
+	var dnsErr *net.DNSError
+	var addrErr *net.AddrError
+	if errors.As(err, &dnsErr) || errors.As(err, &addrErr) {
+		return ErrorDNS
+	}
+
+	var netErr net.Error
+	if errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout()) {
+		return ErrorTimeout
+	}
+
+	if errors.Is(err, syscall.ECONNREFUSED) {
+		return ErrorConnectionRefused
+	}
+
+	if isTLSError(err) {
+		return ErrorTLS
+	}
+
+	var opErr *net.OpError
+	if errors.As(err, &opErr) || errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.EHOSTUNREACH) || errors.Is(err, syscall.ENETUNREACH) {
+		return ErrorConnectionFailed
+	}
+	return ErrorProtocol
+}
+
+func isTLSError(err error) bool {
+	var (
+		verifyErr    *tls.CertificateVerificationError
+		recordErr    tls.RecordHeaderError
+		alertErr     tls.AlertError
+		authorityErr x509.UnknownAuthorityError
+		hostnameErr  x509.HostnameError
+		invalidErr   x509.CertificateInvalidError
+	)
+	switch {
+	case errors.As(err, &verifyErr), errors.As(err, &recordErr), errors.As(err, &alertErr),
+		errors.As(err, &authorityErr), errors.As(err, &hostnameErr), errors.As(err, &invalidErr):
+		return true
+	}
+	// Most handshake failures are plain errors from crypto/tls
+	return strings.Contains(err.Error(), "tls: ")
+}
+
+// classifyError wraps err in a CheckError carrying its class, unless it
+// already has one.
+func classifyError(err error) error {
+	var checkErr *CheckError
+	if errors.As(err, &checkErr) && checkErr.class != "" {
+		return err
+	}
+	return NewCheckError(ErrorClassOf(err), err)
+}
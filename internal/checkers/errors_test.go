@@ -0,0 +1,114 @@
+// Copyright (C) 2025 Jeff Rose
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package checkers
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"syscall"
+	"testing"
+)
+
+func TestErrorClassOf(t *testing.T) {
+	dialErr := func(err error) error {
+		return &net.OpError{Op: "dial", Net: "tcp", Err: os.NewSyscallError("connect", err)}
+	}
+
+	tests := []struct {
+		name string
+		err  error
+		want ErrorClass
+	}{
+		{"nil", nil, ""},
+		{"classified", NewCheckError(ErrorHTTPStatus, errors.New("status 503")), ErrorHTTPStatus},
+		{"classified and wrapped", fmt.Errorf("step login: %w", NewCheckError(ErrorAssertion, errors.New("missing"))), ErrorAssertion},
+		{"dns", &net.DNSError{Err: "no such host", Name: "nope.invalid", IsNotFound: true}, ErrorDNS},
+		{"address", &net.AddrError{Err: "missing port in address", Addr: "db1"}, ErrorDNS},
+		{"deadline", fmt.Errorf("request: %w", context.DeadlineExceeded), ErrorTimeout},
+		{"dns timeout", &net.DNSError{Err: "i/o timeout", IsTimeout: true}, ErrorDNS},
+		{"read timeout", &net.OpError{Op: "read", Net: "tcp", Err: os.ErrDeadlineExceeded}, ErrorTimeout},
+		{"refused", dialErr(syscall.ECONNREFUSED), ErrorConnectionRefused},
+		{"reset", fmt.Errorf("read: %w", syscall.ECONNRESET), ErrorConnectionFailed},
+		{"unreachable", dialErr(syscall.EHOSTUNREACH), ErrorConnectionFailed},
+		{"tls verification", fmt.Errorf("get: %w", x509.UnknownAuthorityError{}), ErrorTLS},
+		{"tls handshake", errors.New("remote error: tls: handshake failure"), ErrorTLS},
+		{"proxy refused", fmt.Errorf("proxy socks5://bastion: %w", &proxyTargetError{state: PortClosed, err: errors.New("connection refused")}), ErrorConnectionRefused},
+		{"proxy unreachable target", &proxyTargetError{state: PortFiltered, err: errors.New("host unreachable")}, ErrorConnectionFailed},
+		{"proxy down", &proxyUnreachableError{err: dialErr(syscall.ECONNREFUSED)}, ErrorConnectionRefused},
+		{"other", errors.New("unexpected greeting"), ErrorProtocol},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ErrorClassOf(tt.err); got != tt.want {
+				t.Errorf("ErrorClassOf(%v) = %q, want %q", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClassifyError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want ErrorClass
+	}{
+		{"unclassified", &net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED}, ErrorConnectionRefused},
+		{"kept", NewCheckError(ErrorAssertion, errors.New("content changed")), ErrorAssertion},
+		{"wrapped", fmt.Errorf("crawl: %w", NewCheckError(ErrorHTTPStatus, errors.New("404"))), ErrorHTTPStatus},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := classifyError(tt.err)
+			var checkErr *CheckError
+			if !errors.As(err, &checkErr) {
+				t.Fatalf("classifyError(%v) = %T, want a *CheckError", tt.err, err)
+			}
+			if got := ErrorClassOf(err); got != tt.want {
+				t.Errorf("class = %q, want %q", got, tt.want)
+			}
+			if !errors.Is(err, tt.err) {
+				t.Errorf("classifyError(%v) lost the original error", tt.err)
+			}
+		})
+	}
+}
+
+func TestPortState(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{"refused", &net.OpError{Op: "dial", Err: syscall.ECONNREFUSED}, PortClosed},
+		{"unreachable", &net.OpError{Op: "dial", Err: syscall.EHOSTUNREACH}, PortFiltered},
+		{"timeout", context.DeadlineExceeded, PortFiltered},
+		{"dns", &net.DNSError{Err: "no such host", IsNotFound: true}, ""},
+		{"proxy refused", fmt.Errorf("proxy http://squid: %w", &proxyTargetError{state: PortClosed, err: errors.New("connect refused: 503 Service Unavailable")}), PortClosed},
+		{"proxy timeout", &proxyTargetError{state: PortFiltered, err: errors.New("connect refused: 504 Gateway Timeout")}, PortFiltered},
+		{"proxy down", &proxyUnreachableError{err: &net.OpError{Op: "dial", Err: syscall.ECONNREFUSED}}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := portState(tt.err); got != tt.want {
+				t.Errorf("portState(%v) = %q, want %q", tt.err, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,314 @@
+// Copyright (C) 2025 Jeff Rose
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package checkers
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+const (
+	execMinTimeout     = 1 * time.Second
+	execMaxTimeout     = 60 * time.Second
+	execDefaultTimeout = 10 * time.Second
+)
+
+// Exit codes defined by the Nagios/Monitoring Plugins guidelines.
+const (
+	PluginOK       = 0
+	PluginWarning  = 1
+	PluginCritical = 2
+	PluginUnknown  = 3
+)
+
+var pluginStatusNames = map[int]string{
+	PluginOK:       "OK",
+	PluginWarning:  "WARNING",
+	PluginCritical: "CRITICAL",
+	PluginUnknown:  "UNKNOWN",
+}
+
+// PerfData is a single performance data entry reported by a plugin, in the
+// form 'label'=value[UOM];[warn];[crit];[min];[max].
+type PerfData struct {
+	Label string
+	Value float64
+	UOM   string
+	Warn  string
+	Crit  string
+	Min   string
+	Max   string
+}
+
+// ExecChecker runs a Nagios compatible plugin for every host.
+type ExecChecker struct {
+	BaseChecker
+	command      []string
+	allowWarning bool
+}
+
+func NewExecChecker() *ExecChecker {
+	return &ExecChecker{
+		BaseChecker: NewBaseChecker(TimeoutBounds{
+			Min:     execMinTimeout,
+			Max:     execMaxTimeout,
+			Default: execDefaultTimeout,
+		}),
+	}
+}
+
+func (c *ExecChecker) Protocol() Protocol {
+	return "EXEC"
+}
+
+func (c *ExecChecker) Configure(opts Options) error {
+	c.command = opts.StringSlice("command")
+	if len(c.command) == 1 {
+		var err error
+		if c.command, err = splitCommand(c.command[0]); err != nil {
+			return fmt.Errorf("invalid command: %w", err)
+		}
+	}
+	if len(c.command) == 0 {
+		return errors.New("command must be specified")
+	}
+	c.allowWarning = opts.Bool("allow_warning", false)
+	return nil
+}
+
+func (c *ExecChecker) Check(ctx context.Context, hosts []string, port string) []HostCheckResult {
+	return c.BaseChecker.Check(ctx, hosts, port, c.checkExec)
+}
+
+func (c *ExecChecker) checkExec(ctx context.Context, host string, port string) (map[string]interface{}, error) {
+	replacer := strings.NewReplacer("{{host}}", host, "{{port}}", port)
+	args := make([]string, len(c.command))
+	for i, arg := range c.command {
+		args[i] = replacer.Replace(arg)
+	}
+
+	var stdout bytes.Buffer
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	cmd.Stdout = &stdout
+	cmd.WaitDelay = time.Second
+
+	exitCode := PluginOK
+	if err := cmd.Run(); err != nil {
+		var exitErr *exec.ExitError
+		switch {
+		case ctx.Err() != nil:
+			return nil, fmt.Errorf("plugin timed out: %w", ctx.Err())
+		case errors.As(err, &exitErr):
+			exitCode = exitErr.ExitCode()
+		default:
+			return nil, fmt.Errorf("plugin execution failed: %w", err)
+		}
+	}
+
+	output, perfData := parsePluginOutput(stdout.String())
+	status, ok := pluginStatusNames[exitCode]
+	if !ok {
+		status = pluginStatusNames[PluginUnknown]
+	}
+
+	metadata := map[string]interface{}{
+		"exit_code": exitCode,
+		"status":    status,
+		"output":    output,
+		"perfdata":  perfData,
+	}
+	for _, pd := range perfData {
+		metadata["perf_"+perfDataKey(pd.Label)] = pd.Value
+	}
+
+	if exitCode == PluginOK || (exitCode == PluginWarning && c.allowWarning) {
+		return metadata, nil
+	}
+	return metadata, NewCheckError(ErrorAssertion, fmt.Errorf("plugin returned %s: %s", status, output))
+}
+
+// splitCommand splits a command line into arguments like a POSIX shell,
+// honouring single quotes, double quotes and backslash escapes, without
+// expanding anything.
+func splitCommand(s string) ([]string, error) {
+	var args []string
+	var current strings.Builder
+	inArg := false
+	var quote rune
+	escaped := false
+
+	for _, r := range s {
+		switch {
+		case escaped:
+			// Inside double quotes a backslash only escapes these
+			if quote == '"' && !strings.ContainsRune("$`\"\\\n", r) {
+				current.WriteRune('\\')
+			}
+			current.WriteRune(r)
+			escaped = false
+		case quote == '\'':
+			if r == '\'' {
+				quote = 0
+			} else {
+				current.WriteRune(r)
+			}
+		case r == '\\' && quote == '"':
+			escaped = true
+		case quote == '"':
+			if r == '"' {
+				quote = 0
+			} else {
+				current.WriteRune(r)
+			}
+		case r == '\\':
+			escaped, inArg = true, true
+		case r == '\'' || r == '"':
+			quote, inArg = r, true
+		case unicode.IsSpace(r):
+			if inArg {
+				args = append(args, current.String())
+				current.Reset()
+				inArg = false
+			}
+		default:
+			current.WriteRune(r)
+			inArg = true
+		}
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated %c quote", quote)
+	}
+	if escaped {
+		return nil, errors.New("trailing backslash")
+	}
+	if inArg {
+		args = append(args, current.String())
+	}
+	return args, nil
+}
+
+// parsePluginOutput splits plugin output into the first line of text and the
+// performance data. That is the part of the first line after '|' and, in the
+// long text that follows, everything after the first '|', including later
+// lines without one.
+func parsePluginOutput(output string) (string, []PerfData) {
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+
+	text, perf, _ := strings.Cut(lines[0], "|")
+	perfData := parsePerfData(perf)
+
+	inPerfData := false
+	for _, line := range lines[1:] {
+		if !inPerfData {
+			if _, line, inPerfData = strings.Cut(line, "|"); !inPerfData {
+				continue
+			}
+		}
+		perfData = append(perfData, parsePerfData(line)...)
+	}
+	return strings.TrimSpace(text), perfData
+}
+
+func parsePerfData(s string) []PerfData {
+	var entries []PerfData
+	for _, token := range splitPerfData(s) {
+		label, rest, ok := strings.Cut(token, "=")
+		if !ok {
+			continue
+		}
+
+		fields := strings.Split(rest, ";")
+		value, uom := splitUOM(fields[0])
+		v, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			continue
+		}
+
+		pd := PerfData{
+			Label: strings.Trim(label, "'"),
+			Value: v,
+			UOM:   uom,
+		}
+		thresholds := []*string{&pd.Warn, &pd.Crit, &pd.Min, &pd.Max}
+		for i, field := range fields[1:] {
+			if i < len(thresholds) {
+				*thresholds[i] = field
+			}
+		}
+		entries = append(entries, pd)
+	}
+	return entries
+}
+
+// splitPerfData splits on whitespace while keeping quoted labels intact.
+func splitPerfData(s string) []string {
+	var tokens []string
+	var current strings.Builder
+	quoted := false
+
+	for _, r := range s {
+		switch {
+		case r == '\'':
+			quoted = !quoted
+			current.WriteRune(r)
+		case unicode.IsSpace(r) && !quoted:
+			if current.Len() > 0 {
+				tokens = append(tokens, current.String())
+				current.Reset()
+			}
+		default:
+			current.WriteRune(r)
+		}
+	}
+	if current.Len() > 0 {
+		tokens = append(tokens, current.String())
+	}
+	return tokens
+}
+
+func splitUOM(s string) (string, string) {
+	i := strings.IndexFunc(s, func(r rune) bool {
+		return !unicode.IsDigit(r) && r != '.' && r != '-' && r != '+' && r != 'e' && r != 'E'
+	})
+	if i < 0 {
+		return s, ""
+	}
+	return s[:i], s[i:]
+}
+
+// perfDataKey turns a perfdata label into a metadata key usable in rules.
+func perfDataKey(label string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(label) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+		} else {
+			b.WriteRune('_')
+		}
+	}
+	return b.String()
+}
+
+func init() {
+	RegisterChecker("EXEC", func() Checker { return NewExecChecker() })
+}
@@ -0,0 +1,90 @@
+// Copyright (C) 2025 Jeff Rose
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package checkers
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestExecConfigureSplitsCommand(t *testing.T) {
+	tests := []struct {
+		command interface{}
+		want    []string
+		wantErr bool
+	}{
+		{command: "check_http -H {{host}} -p {{port}}", want: []string{"check_http", "-H", "{{host}}", "-p", "{{port}}"}},
+		{command: `check_http -u "/a b" -s 'it''s'`, want: []string{"check_http", "-u", "/a b", "-s", "its"}},
+		{command: `check_http -s "say \"hi\"" -e a\ b ''`, want: []string{"check_http", "-s", `say "hi"`, "-e", "a b", ""}},
+		{command: []interface{}{"check_http", "-u", "/a b"}, want: []string{"check_http", "-u", "/a b"}},
+		{command: `check_http -r "^a\.b$" -R '\d'`, want: []string{"check_http", "-r", `^a\.b$`, "-R", `\d`}},
+		{command: `check_http -u "/a b`, wantErr: true},
+		{command: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		c := NewExecChecker()
+		err := c.Configure(Options{"command": tt.command})
+		if (err != nil) != tt.wantErr {
+			t.Errorf("command %q: Configure() error = %v, want error %v", tt.command, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && !slices.Equal(c.command, tt.want) {
+			t.Errorf("command %q: split into %q, want %q", tt.command, c.command, tt.want)
+		}
+	}
+}
+
+func TestParsePluginOutput(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		text   string
+		labels []string
+	}{
+		{
+			name:   "single line",
+			output: "OK - 12 ms | time=0.012s;1;2;0 size=512B",
+			text:   "OK - 12 ms",
+			labels: []string{"time", "size"},
+		},
+		{
+			name:   "long text without perfdata",
+			output: "DISK OK\n/ 40%\n/var 62%\n",
+			text:   "DISK OK",
+		},
+		{
+			name:   "long text perfdata continues on later lines",
+			output: "DISK OK | /=40%\n/ 40% used\n/var 62% used | /var=62%;80;90\n/home=10%;80;90\n'/mnt data'=5%\n",
+			text:   "DISK OK",
+			labels: []string{"/", "/var", "/home", "/mnt data"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			text, perfData := parsePluginOutput(tt.output)
+			if text != tt.text {
+				t.Errorf("text = %q, want %q", text, tt.text)
+			}
+			var labels []string
+			for _, pd := range perfData {
+				labels = append(labels, pd.Label)
+			}
+			if !slices.Equal(labels, tt.labels) {
+				t.Errorf("perfdata labels = %q, want %q", labels, tt.labels)
+			}
+		})
+	}
+}
@@ -0,0 +1,79 @@
+// Copyright (C) 2025 Jeff Rose
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package checkers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"syscall"
+)
+
+const (
+	PortOpen     = "open"
+	PortClosed   = "closed"
+	PortFiltered = "filtered"
+)
+
+// expectClosed reports whether the check is a negative check, where
+// success means the port cannot be reached.
+func expectClosed(opts Options) (bool, error) {
+	switch expect := opts.String("expect", PortOpen); expect {
+	case PortOpen:
+		return false, nil
+	case PortClosed:
+		return true, nil
+	default:
+		return false, fmt.Errorf("invalid expect %q, must be open or closed", expect)
+	}
+}
+
+// portState classifies a failed connection attempt. It returns "" for
+// errors that say nothing about the port, such as DNS failures or an
+// unreachable proxy.
+func portState(err error) string {
+	var netErr net.Error
+	var targetErr *proxyTargetError
+	var proxyErr *proxyUnreachableError
+	switch {
+	case errors.As(err, &targetErr):
+		return targetErr.state
+	case errors.As(err, &proxyErr):
+		return ""
+	case errors.Is(err, syscall.ECONNREFUSED), errors.Is(err, syscall.ECONNRESET):
+		return PortClosed
+	case errors.Is(err, syscall.EHOSTUNREACH), errors.Is(err, syscall.ENETUNREACH):
+		return PortFiltered
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		return PortFiltered
+	}
+	return ""
+}
+
+// verifyClosed turns the outcome of a connection attempt into the result
+// of a negative check: a refused or filtered port passes, an established
+// connection fails.
+func verifyClosed(err error) (map[string]interface{}, error) {
+	if err == nil {
+		return map[string]interface{}{"port_state": PortOpen}, NewCheckError(ErrorAssertion, errors.New("port is open, expected closed"))
+	}
+	state := portState(err)
+	if state == "" {
+		return nil, err
+	}
+	return map[string]interface{}{"port_state": state}, nil
+}
@@ -0,0 +1,158 @@
+// Copyright (C) 2025 Jeff Rose
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package checkers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Address families a check can be restricted to. FamilyBoth is resolved by
+// the monitor into one checker per family, so checkers themselves only see
+// FamilyIPv4, FamilyIPv6 or no restriction at all.
+const (
+	FamilyIPv4 = "ipv4"
+	FamilyIPv6 = "ipv6"
+	FamilyBoth = "both"
+)
+
+// addressFamily reads the address_family option. An empty result means
+// either family may be used.
+func addressFamily(opts Options) (string, error) {
+	family := strings.ToLower(opts.String("address_family", ""))
+	switch family {
+	case "", FamilyIPv4, FamilyIPv6:
+		return family, nil
+	default:
+		return "", fmt.Errorf("unsupported address_family %q, expected ipv4 or ipv6", family)
+	}
+}
+
+// familyNetwork narrows a generic tcp or udp network to the given family.
+func familyNetwork(network, family string) string {
+	if network != "tcp" && network != "udp" {
+		return network
+	}
+	switch family {
+	case FamilyIPv4:
+		return network + "4"
+	case FamilyIPv6:
+		return network + "6"
+	}
+	return network
+}
+
+// lookupNetwork returns the network name net.Resolver.LookupIP expects for
+// the given family.
+func lookupNetwork(family string) string {
+	switch family {
+	case FamilyIPv4:
+		return "ip4"
+	case FamilyIPv6:
+		return "ip6"
+	}
+	return "ip"
+}
+
+func ipFamily(ip net.IP) string {
+	if ip.To4() != nil {
+		return FamilyIPv4
+	}
+	return FamilyIPv6
+}
+
+// resolveFamily replaces the host of address with one of its addresses in
+// the given family. Proxies resolve names themselves, so this is how a
+// family restriction reaches the far side of a proxy.
+func resolveFamily(ctx context.Context, address, family string) (string, error) {
+	if family == "" {
+		return address, nil
+	}
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return "", err
+	}
+
+	if ip := net.ParseIP(host); ip != nil {
+		if ipFamily(ip) != family {
+			return "", fmt.Errorf("%s is not an %s address", host, family)
+		}
+		return address, nil
+	}
+
+	ips, err := net.DefaultResolver.LookupIP(ctx, lookupNetwork(family), host)
+	if err != nil {
+		return "", fmt.Errorf("dns lookup failed: %w", err)
+	}
+	return net.JoinHostPort(ips[0].String(), port), nil
+}
+
+// DualStackChecker runs a check once over IPv4 and once over IPv6, so a
+// host that only works on one family shows up as half down instead of
+// passing on whichever family the resolver prefers.
+type DualStackChecker struct {
+	ipv4 Checker
+	ipv6 Checker
+}
+
+// NewDualStackChecker combines two checkers of the same protocol, configured
+// with address_family ipv4 and ipv6 respectively.
+func NewDualStackChecker(ipv4, ipv6 Checker) *DualStackChecker {
+	return &DualStackChecker{ipv4: ipv4, ipv6: ipv6}
+}
+
+func (c *DualStackChecker) Protocol() Protocol {
+	return c.ipv4.Protocol()
+}
+
+func (c *DualStackChecker) Check(ctx context.Context, hosts []string, port string) []HostCheckResult {
+	var v4, v6 []HostCheckResult
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		v4 = c.ipv4.Check(ctx, hosts, port)
+	}()
+	go func() {
+		defer wg.Done()
+		v6 = c.ipv6.Check(ctx, hosts, port)
+	}()
+	wg.Wait()
+
+	results := make([]HostCheckResult, 0, len(v4)+len(v6))
+	for _, result := range v4 {
+		result.Family = FamilyIPv4
+		results = append(results, result)
+	}
+	for _, result := range v6 {
+		result.Family = FamilyIPv6
+		results = append(results, result)
+	}
+	return results
+}
+
+func (c *DualStackChecker) GetTimeout() time.Duration {
+	return c.ipv4.GetTimeout()
+}
+
+func (c *DualStackChecker) SetTimeout(timeout time.Duration) error {
+	return errors.Join(c.ipv4.SetTimeout(timeout), c.ipv6.SetTimeout(timeout))
+}
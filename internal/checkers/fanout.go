@@ -0,0 +1,161 @@
+// Copyright (C) 2025 Jeff Rose
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package checkers
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+)
+
+// FanOutChecker resolves each host and runs the wrapped checker once per
+// address, so a single bad backend behind a load-balanced name is reported
+// on its own. The checks still use the host name, for TLS server names and
+// Host headers; only the connection is pinned to the address.
+type FanOutChecker struct {
+	inner  Checker
+	family string
+}
+
+// NewFanOutChecker wraps a checker configured with the resolve_all option.
+// Family limits resolution to A or AAAA records, empty resolves both.
+func NewFanOutChecker(inner Checker, family string) *FanOutChecker {
+	return &FanOutChecker{inner: inner, family: family}
+}
+
+func (c *FanOutChecker) Protocol() Protocol {
+	return c.inner.Protocol()
+}
+
+func (c *FanOutChecker) Check(ctx context.Context, hosts []string, port string) []HostCheckResult {
+	perHost := make([][]HostCheckResult, len(hosts))
+
+	var wg sync.WaitGroup
+	wg.Add(len(hosts))
+	for i, host := range hosts {
+		go func(index int, host string) {
+			defer wg.Done()
+			perHost[index] = c.checkAddresses(ctx, host, port)
+		}(i, host)
+	}
+	wg.Wait()
+
+	var results []HostCheckResult
+	for _, hostResults := range perHost {
+		results = append(results, hostResults...)
+	}
+	return results
+}
+
+func (c *FanOutChecker) checkAddresses(ctx context.Context, host string, port string) []HostCheckResult {
+	start := time.Now()
+	ips, err := c.resolve(ctx, host)
+	if err != nil {
+		return []HostCheckResult{{
+			Host: host,
+			CheckResult: CheckResult{
+				Error:        err,
+				ResponseTime: time.Since(start),
+			},
+		}}
+	}
+
+	results := make([]HostCheckResult, len(ips))
+	var wg sync.WaitGroup
+	wg.Add(len(ips))
+	for i, ip := range ips {
+		go func(index int, ip net.IP) {
+			defer wg.Done()
+			pinned := withPinnedAddress(ctx, host, ip)
+			result := c.inner.Check(pinned, []string{host}, port)[0]
+			result.Address = ip.String()
+			results[index] = result
+		}(i, ip)
+	}
+	wg.Wait()
+	return results
+}
+
+func (c *FanOutChecker) resolve(ctx context.Context, host string) ([]net.IP, error) {
+	if ip := net.ParseIP(host); ip != nil {
+		return []net.IP{ip}, nil
+	}
+
+	lookupCtx, cancel := context.WithTimeout(ctx, c.inner.GetTimeout())
+	defer cancel()
+
+	ips, err := net.DefaultResolver.LookupIP(lookupCtx, lookupNetwork(c.family), host)
+	if err != nil {
+		return nil, fmt.Errorf("dns lookup failed: %w", err)
+	}
+	return ips, nil
+}
+
+func (c *FanOutChecker) GetTimeout() time.Duration {
+	return c.inner.GetTimeout()
+}
+
+func (c *FanOutChecker) SetTimeout(timeout time.Duration) error {
+	return c.inner.SetTimeout(timeout)
+}
+
+type pinnedAddressKey struct{}
+
+type pinnedAddress struct {
+	host string
+	ip   net.IP
+}
+
+func withPinnedAddress(ctx context.Context, host string, ip net.IP) context.Context {
+	return context.WithValue(ctx, pinnedAddressKey{}, pinnedAddress{host: host, ip: ip})
+}
+
+// pinAddress replaces the host of address with the address it is pinned to
+// in ctx. Connections to other hosts, such as an IMAP server or an RDAP
+// registry, are left alone.
+func pinAddress(ctx context.Context, address string) string {
+	pinned, ok := ctx.Value(pinnedAddressKey{}).(pinnedAddress)
+	if !ok {
+		return address
+	}
+	host, port, err := net.SplitHostPort(address)
+	if err != nil || host != pinned.host {
+		return address
+	}
+	return net.JoinHostPort(pinned.ip.String(), port)
+}
+
+// stateKey identifies a host and port in state kept between runs, such as
+// a scan or page hash baseline, so instances sharing an address and each
+// pinned address keep their own history.
+func stateKey(ctx context.Context, host, port string) string {
+	key := net.JoinHostPort(host, port)
+	if pinned, ok := ctx.Value(pinnedAddressKey{}).(pinnedAddress); ok && pinned.host == host {
+		return key + "@" + pinned.ip.String()
+	}
+	return key
+}
+
+// pinnedDialer dials the address a host is pinned to by a FanOutChecker.
+type pinnedDialer struct {
+	Dialer
+}
+
+func (d *pinnedDialer) DialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	return d.Dialer.DialContext(ctx, network, pinAddress(ctx, address))
+}
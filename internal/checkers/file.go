@@ -0,0 +1,203 @@
+// Copyright (C) 2025 Jeff Rose
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package checkers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	fileMinTimeout     = 1 * time.Second
+	fileMaxTimeout     = 30 * time.Second
+	fileDefaultTimeout = 10 * time.Second
+
+	fileMaxContentBytes = 10 << 20
+)
+
+// FileChecker verifies that the newest file matching a path or glob on the
+// local machine is fresh, sized within bounds and optionally has matching
+// contents.
+type FileChecker struct {
+	BaseChecker
+	path         string
+	maxAge       time.Duration
+	minSize      int64
+	maxSize      int64
+	contentRegex *regexp.Regexp
+}
+
+func NewFileChecker() *FileChecker {
+	return &FileChecker{
+		BaseChecker: NewBaseChecker(TimeoutBounds{
+			Min:     fileMinTimeout,
+			Max:     fileMaxTimeout,
+			Default: fileDefaultTimeout,
+		}),
+	}
+}
+
+func (c *FileChecker) Protocol() Protocol {
+	return "FILE"
+}
+
+func (c *FileChecker) Configure(opts Options) error {
+	c.path = opts.String("path", "")
+	if c.path == "" {
+		return errors.New("path must be specified")
+	}
+	if _, err := filepath.Match(c.path, ""); err != nil {
+		return fmt.Errorf("invalid path pattern: %w", err)
+	}
+
+	var err error
+	if c.maxAge, err = opts.Duration("max_age", 0); err != nil {
+		return err
+	}
+	if c.minSize, err = parseSize(opts.String("min_size", "0")); err != nil {
+		return fmt.Errorf("option min_size: %w", err)
+	}
+	if c.maxSize, err = parseSize(opts.String("max_size", "0")); err != nil {
+		return fmt.Errorf("option max_size: %w", err)
+	}
+
+	if pattern := opts.String("content_regex", ""); pattern != "" {
+		if c.contentRegex, err = regexp.Compile(pattern); err != nil {
+			return fmt.Errorf("option content_regex: %w", err)
+		}
+	}
+	return nil
+}
+
+func (c *FileChecker) Check(ctx context.Context, hosts []string, port string) []HostCheckResult {
+	return c.BaseChecker.Check(ctx, hosts, port, func(ctx context.Context, host string, _ string) (map[string]interface{}, error) {
+		return localCheck(ctx, host, c.checkFile)
+	})
+}
+
+func (c *FileChecker) checkFile() (map[string]interface{}, error) {
+	matches, err := filepath.Glob(c.path)
+	if err != nil {
+		return nil, fmt.Errorf("invalid path pattern: %w", err)
+	}
+
+	var newest string
+	var newestInfo os.FileInfo
+	for _, match := range matches {
+		info, err := os.Stat(match)
+		if err != nil || info.IsDir() {
+			continue
+		}
+		if newestInfo == nil || info.ModTime().After(newestInfo.ModTime()) {
+			newest, newestInfo = match, info
+		}
+	}
+
+	metadata := map[string]interface{}{
+		"matches": len(matches),
+	}
+	if newestInfo == nil {
+		return metadata, NewCheckError(ErrorAssertion, fmt.Errorf("no files match %s", c.path))
+	}
+
+	age := time.Since(newestInfo.ModTime())
+	metadata["path"] = newest
+	metadata["age"] = age
+	metadata["size_bytes"] = newestInfo.Size()
+
+	var problems []string
+	if c.maxAge > 0 && age > c.maxAge {
+		problems = append(problems, fmt.Sprintf("%s is %s old (max %s)", newest, age.Round(time.Second), c.maxAge))
+	}
+	if newestInfo.Size() < c.minSize {
+		problems = append(problems, fmt.Sprintf("%s is %d bytes (min %d)", newest, newestInfo.Size(), c.minSize))
+	}
+	if c.maxSize > 0 && newestInfo.Size() > c.maxSize {
+		problems = append(problems, fmt.Sprintf("%s is %d bytes (max %d)", newest, newestInfo.Size(), c.maxSize))
+	}
+
+	if c.contentRegex != nil {
+		matched, err := fileContentMatches(newest, c.contentRegex)
+		if err != nil {
+			return metadata, err
+		}
+		metadata["content_matched"] = matched
+		if !matched {
+			problems = append(problems, fmt.Sprintf("%s does not match %s", newest, c.contentRegex))
+		}
+	}
+
+	if len(problems) > 0 {
+		return metadata, NewCheckError(ErrorAssertion, errors.New(strings.Join(problems, ", ")))
+	}
+	return metadata, nil
+}
+
+func fileContentMatches(path string, re *regexp.Regexp) (bool, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return false, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer f.Close()
+
+	data, err := io.ReadAll(io.LimitReader(f, fileMaxContentBytes))
+	if err != nil {
+		return false, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return re.Match(data), nil
+}
+
+// parseSize accepts a byte count with an optional K, M, G or T suffix
+// (powers of 1024), e.g. "512", "10MB" or "1.5G".
+func parseSize(s string) (int64, error) {
+	s = strings.ToUpper(strings.TrimSpace(s))
+	s = strings.TrimSuffix(strings.TrimSuffix(s, "B"), "I")
+
+	multiplier := int64(1)
+	if s != "" {
+		switch s[len(s)-1] {
+		case 'K':
+			multiplier = 1 << 10
+		case 'M':
+			multiplier = 1 << 20
+		case 'G':
+			multiplier = 1 << 30
+		case 'T':
+			multiplier = 1 << 40
+		}
+		if multiplier > 1 {
+			s = s[:len(s)-1]
+		}
+	}
+
+	value, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	if err != nil || value < 0 {
+		return 0, fmt.Errorf("invalid size %q", s)
+	}
+	return int64(value * float64(multiplier)), nil
+}
+
+func init() {
+	RegisterChecker("FILE", func() Checker { return NewFileChecker() })
+}
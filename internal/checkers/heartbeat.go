@@ -0,0 +1,132 @@
+// Copyright (C) 2025 Jeff Rose
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package checkers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/whiskeyjimbo/CheckMate/internal/heartbeat"
+)
+
+const (
+	heartbeatDefaultGrace = 1 * time.Minute
+)
+
+// HeartbeatChecker is a passive check: it does not contact the hosts but
+// verifies that pings for its token arrived on the heartbeat endpoint in time.
+type HeartbeatChecker struct {
+	BaseChecker
+	token  string
+	period time.Duration
+	grace  time.Duration
+}
+
+func NewHeartbeatChecker() *HeartbeatChecker {
+	return &HeartbeatChecker{
+		BaseChecker: NewBaseChecker(TimeoutBounds{}),
+		grace:       heartbeatDefaultGrace,
+	}
+}
+
+func (c *HeartbeatChecker) Protocol() Protocol {
+	return "HEARTBEAT"
+}
+
+// Configure registers the tokens of all targets, given by the monitor as
+// host:port entries, so pings are accepted from startup and a token can
+// only be used by one check.
+func (c *HeartbeatChecker) Configure(opts Options) error {
+	c.token = opts.String("token", "")
+	if c.token == "" {
+		return errors.New("token must be specified")
+	}
+
+	var err error
+	if c.period, err = opts.Duration("period", 0); err != nil {
+		return err
+	}
+	if c.period <= 0 {
+		return errors.New("period must be specified")
+	}
+	if c.grace, err = opts.Duration("grace", heartbeatDefaultGrace); err != nil {
+		return err
+	}
+
+	var tokens []string
+	for _, target := range opts.StringSlice("targets") {
+		host, port, err := net.SplitHostPort(target)
+		if err != nil {
+			return fmt.Errorf("invalid target %q: %w", target, err)
+		}
+		if token := c.tokenFor(host, port); !slices.Contains(tokens, token) {
+			tokens = append(tokens, token)
+		}
+	}
+	return heartbeat.Claim(tokens...)
+}
+
+func (c *HeartbeatChecker) Check(ctx context.Context, hosts []string, port string) []HostCheckResult {
+	return c.BaseChecker.Check(ctx, hosts, port, c.checkHeartbeat)
+}
+
+func (c *HeartbeatChecker) checkHeartbeat(_ context.Context, host string, port string) (map[string]interface{}, error) {
+	token := c.tokenFor(host, port)
+	status, _ := heartbeat.Get(token)
+	now := time.Now()
+
+	metadata := map[string]interface{}{
+		"token":       token,
+		"last_signal": string(status.LastSignal),
+	}
+
+	// Until the first ping we measure from registration so a fresh start
+	// does not immediately report a missed heartbeat.
+	lastSeen := status.Registered
+	if !status.LastPing.IsZero() {
+		lastSeen = status.LastPing
+		metadata["last_ping"] = status.LastPing
+		metadata["last_duration"] = status.LastDuration
+	}
+	sinceLastPing := now.Sub(lastSeen)
+	metadata["since_last_ping"] = sinceLastPing
+
+	switch {
+	case status.LastSignal == heartbeat.SignalFail:
+		return metadata, NewCheckError(ErrorAssertion, errors.New("job reported failure"))
+	case status.LastSignal == heartbeat.SignalStart && now.Sub(status.LastStart) > c.grace:
+		return metadata, NewCheckError(ErrorAssertion, fmt.Errorf("job started %s ago and has not finished", now.Sub(status.LastStart).Round(time.Second)))
+	case sinceLastPing > c.period+c.grace:
+		if status.LastPing.IsZero() {
+			return metadata, NewCheckError(ErrorAssertion, errors.New("no heartbeat received"))
+		}
+		return metadata, NewCheckError(ErrorAssertion, fmt.Errorf("last heartbeat %s ago, expected every %s", sinceLastPing.Round(time.Second), c.period))
+	}
+	return metadata, nil
+}
+
+func (c *HeartbeatChecker) tokenFor(host, port string) string {
+	return strings.NewReplacer("{{host}}", host, "{{port}}", port).Replace(c.token)
+}
+
+func init() {
+	RegisterChecker("HEARTBEAT", func() Checker { return NewHeartbeatChecker() })
+}
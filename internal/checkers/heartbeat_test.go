@@ -0,0 +1,47 @@
+// Copyright (C) 2025 Jeff Rose
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package checkers
+
+import (
+	"testing"
+
+	"github.com/whiskeyjimbo/CheckMate/internal/heartbeat"
+)
+
+func TestHeartbeatConfigureRegistersTokens(t *testing.T) {
+	opts := Options{
+		"token":   "backup-{{host}}",
+		"period":  "1h",
+		"targets": []string{"db1:0", "db2:0"},
+	}
+	if err := NewHeartbeatChecker().Configure(opts); err != nil {
+		t.Fatalf("Configure: %v", err)
+	}
+	for _, token := range []string{"backup-db1", "backup-db2"} {
+		if _, ok := heartbeat.Get(token); !ok {
+			t.Errorf("token %s not registered", token)
+		}
+	}
+
+	if err := NewHeartbeatChecker().Configure(opts); err == nil {
+		t.Error("second check with the same tokens: Configure() = nil, want error")
+	}
+
+	shared := Options{"token": "nightly", "period": "24h", "targets": []string{"web1:80", "web2:80"}}
+	if err := NewHeartbeatChecker().Configure(shared); err != nil {
+		t.Errorf("hosts sharing one token: Configure() = %v, want nil", err)
+	}
+}
@@ -17,9 +17,15 @@ package checkers
 
 import (
 	"context"
+	"crypto/tls"
+	"errors"
 	"fmt"
+	"net"
 	"net/http"
+	"strings"
 	"time"
+
+	"golang.org/x/net/http2"
 )
 
 const (
@@ -30,7 +36,11 @@ const (
 
 type HTTPChecker struct {
 	BaseChecker
-	client *http.Client
+	client      *http.Client
+	httpVersion string
+	content     *contentWatch
+
+	expectClosed bool
 }
 
 func NewHTTPChecker() *HTTPChecker {
@@ -41,7 +51,8 @@ func NewHTTPChecker() *HTTPChecker {
 			Default: httpDefaultTimeout,
 		}),
 		client: &http.Client{
-			Timeout: httpDefaultTimeout,
+			Timeout:   httpDefaultTimeout,
+			Transport: newHTTPTransport(&net.Dialer{}),
 		},
 	}
 }
@@ -50,27 +61,104 @@ func (c *HTTPChecker) Protocol() Protocol {
 	return "HTTP"
 }
 
+func (c *HTTPChecker) Configure(opts Options) error {
+	dialer, err := newDialer(opts)
+	if err != nil {
+		return err
+	}
+
+	c.httpVersion = strings.ToLower(opts.String("http_version", ""))
+	switch c.httpVersion {
+	case "", "1.1":
+		c.client.Transport = newHTTPTransport(dialer)
+	case "h2c":
+		// HTTP/2 over cleartext with prior knowledge, no upgrade dance. Its
+		// connection pool cannot be turned off to keep pinned addresses apart.
+		if _, ok := dialer.(*pinnedDialer); ok {
+			return errors.New("resolve_all is not supported with http_version h2c")
+		}
+		c.client.Transport = &http2.Transport{
+			AllowHTTP: true,
+			DialTLSContext: func(ctx context.Context, network, addr string, _ *tls.Config) (net.Conn, error) {
+				return dialer.DialContext(ctx, network, addr)
+			},
+		}
+	default:
+		return fmt.Errorf("unsupported http_version %q, expected 1.1 or h2c", c.httpVersion)
+	}
+
+	if c.expectClosed, err = expectClosed(opts); err != nil {
+		return err
+	}
+	c.content, err = newContentWatch(opts)
+	return err
+}
+
 func (c *HTTPChecker) Check(ctx context.Context, hosts []string, port string) []HostCheckResult {
 	return c.BaseChecker.Check(ctx, hosts, port, c.checkHTTP)
 }
 
 func (c *HTTPChecker) checkHTTP(ctx context.Context, host string, port string) (map[string]interface{}, error) {
-	url := fmt.Sprintf("http://%s:%s", host, port)
+	url := "http://" + net.JoinHostPort(host, port)
 	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	resp, err := c.client.Do(req)
+	if c.expectClosed {
+		return verifyHTTPClosed(resp, err)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("http request failed: %w", err)
 	}
 	defer resp.Body.Close()
 
+	metadata := map[string]interface{}{
+		"protocol": resp.Proto,
+	}
 	if resp.StatusCode >= 400 {
-		return nil, fmt.Errorf("http status error: %d", resp.StatusCode)
+		return metadata, NewCheckError(ErrorHTTPStatus, fmt.Errorf("http status error: %d", resp.StatusCode))
+	}
+	if err := verifyHTTPVersion(c.httpVersion, resp); err != nil {
+		return metadata, err
+	}
+	if c.content != nil {
+		if err := c.content.verify(stateKey(ctx, host, port), resp.Body, metadata); err != nil {
+			return metadata, err
+		}
+	}
+	return metadata, nil
+}
+
+// verifyHTTPClosed is the negative counterpart of an HTTP check: any
+// response, whatever its status, means the service is exposed.
+func verifyHTTPClosed(resp *http.Response, err error) (map[string]interface{}, error) {
+	if err != nil {
+		return verifyClosed(err)
+	}
+	resp.Body.Close()
+	return map[string]interface{}{"port_state": PortOpen}, NewCheckError(ErrorAssertion, fmt.Errorf("http service answered with status %d, expected closed", resp.StatusCode))
+}
+
+// verifyHTTPVersion checks the negotiated protocol against a required
+// http_version option. An empty requirement accepts anything.
+func verifyHTTPVersion(required string, resp *http.Response) error {
+	var major int
+	switch required {
+	case "":
+		return nil
+	case "1.1":
+		major = 1
+	case "h2", "h2c":
+		major = 2
+	case "h3":
+		major = 3
+	}
+	if resp.ProtoMajor != major {
+		return fmt.Errorf("negotiated %s, expected %s", resp.Proto, required)
 	}
-	return nil, nil
+	return nil
 }
 
 func init() {
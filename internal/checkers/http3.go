@@ -0,0 +1,138 @@
+// Copyright (C) 2025 Jeff Rose
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package checkers
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/quic-go/quic-go"
+	"github.com/quic-go/quic-go/http3"
+)
+
+const (
+	http3MinTimeout     = 3 * time.Second
+	http3MaxTimeout     = 20 * time.Second
+	http3DefaultTimeout = 12 * time.Second
+)
+
+// HTTP3Checker requests each host over HTTP/3, so a broken UDP path fails the
+// check instead of silently falling back to TCP.
+type HTTP3Checker struct {
+	BaseChecker
+	verifyCert bool
+	source     *sourceDialer
+}
+
+func NewHTTP3Checker() *HTTP3Checker {
+	return &HTTP3Checker{
+		BaseChecker: NewBaseChecker(TimeoutBounds{
+			Min:     http3MinTimeout,
+			Max:     http3MaxTimeout,
+			Default: http3DefaultTimeout,
+		}),
+		source: &sourceDialer{},
+	}
+}
+
+func (c *HTTP3Checker) Protocol() Protocol {
+	return "HTTP3"
+}
+
+func (c *HTTP3Checker) Configure(opts Options) error {
+	c.verifyCert = opts.Bool("verify_cert", false)
+
+	// QUIC runs over UDP, which neither proxy type can carry. Pinned
+	// addresses are applied when dialing, so the route dialer is enough.
+	dialer, err := newRouteDialer(opts)
+	if err != nil {
+		return err
+	}
+	if isProxied(dialer) {
+		return errors.New("HTTP3 cannot be checked through a proxy")
+	}
+	c.source = &sourceDialer{}
+	if source, ok := routeOf(dialer).(*sourceDialer); ok {
+		c.source = source
+	}
+	return nil
+}
+
+func (c *HTTP3Checker) Check(ctx context.Context, hosts []string, port string) []HostCheckResult {
+	return c.BaseChecker.Check(ctx, hosts, port, c.checkHTTP3)
+}
+
+func (c *HTTP3Checker) checkHTTP3(ctx context.Context, host string, port string) (map[string]interface{}, error) {
+	url := "https://" + net.JoinHostPort(host, port)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	// A transport per check forces a fresh QUIC handshake every round.
+	transport := &http3.Transport{
+		TLSClientConfig: &tls.Config{
+			InsecureSkipVerify: !c.verifyCert,
+		},
+	}
+
+	conn, err := c.source.ListenPacket(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open udp socket: %w", err)
+	}
+	// Deferred before transport.Close so the socket outlives the connection
+	defer conn.Close()
+
+	transport.Dial = func(ctx context.Context, addr string, tlsCfg *tls.Config, cfg *quic.Config) (quic.EarlyConnection, error) {
+		udpAddr, err := net.ResolveUDPAddr(familyNetwork("udp", c.source.family), pinAddress(ctx, addr))
+		if err != nil {
+			return nil, err
+		}
+		return quic.DialEarly(ctx, conn, udpAddr, tlsCfg, cfg)
+	}
+	defer transport.Close()
+
+	resp, err := transport.RoundTrip(req)
+	if err != nil {
+		return nil, fmt.Errorf("http3 request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	metadata := map[string]interface{}{
+		"protocol": resp.Proto,
+	}
+	if resp.TLS != nil && len(resp.TLS.PeerCertificates) > 0 {
+		cert := resp.TLS.PeerCertificates[0]
+		metadata["cert_info"] = &CertInfo{
+			ExpiresAt: cert.NotAfter,
+			IssuedBy:  cert.Issuer.CommonName,
+		}
+	}
+
+	if resp.StatusCode >= 400 {
+		return metadata, NewCheckError(ErrorHTTPStatus, fmt.Errorf("http3 status error: %d", resp.StatusCode))
+	}
+	return metadata, nil
+}
+
+func init() {
+	RegisterChecker("HTTP3", func() Checker { return NewHTTP3Checker() })
+}
@@ -0,0 +1,370 @@
+// Copyright (C) 2025 Jeff Rose
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package checkers
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/cookiejar"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	httpFlowMinTimeout     = 2 * time.Second
+	httpFlowMaxTimeout     = 60 * time.Second
+	httpFlowDefaultTimeout = 30 * time.Second
+
+	httpFlowMaxBodyBytes = 10 << 20
+)
+
+var flowVariablePattern = regexp.MustCompile(`{{\s*([A-Za-z0-9_]+)\s*}}`)
+
+// FlowStep is a single request in a scripted HTTP transaction.
+type FlowStep struct {
+	Name    string            `yaml:"name"`
+	Method  string            `yaml:"method"`
+	Path    string            `yaml:"path"`
+	Headers map[string]string `yaml:"headers"`
+	Body    string            `yaml:"body"`
+	Expect  FlowExpect        `yaml:"expect"`
+	Extract []FlowExtract     `yaml:"extract"`
+}
+
+// FlowExpect lists the assertions for a step. An empty status list accepts
+// any status below 400.
+type FlowExpect struct {
+	Status       []int  `yaml:"status"`
+	BodyContains string `yaml:"body_contains"`
+	BodyRegex    string `yaml:"body_regex"`
+
+	re *regexp.Regexp
+}
+
+// FlowExtract stores a value from a step's response in a variable usable
+// by later steps as {{name}}.
+type FlowExtract struct {
+	Var      string `yaml:"var"`
+	JSONPath string `yaml:"jsonpath"`
+	Regex    string `yaml:"regex"`
+	Header   string `yaml:"header"`
+	Cookie   string `yaml:"cookie"`
+	re       *regexp.Regexp
+}
+
+// FlowStepResult is the per-step outcome reported in metadata.
+type FlowStepResult struct {
+	Name     string
+	Status   int
+	Duration time.Duration
+	Error    string
+}
+
+// HTTPFlowChecker runs an ordered list of HTTP requests against each host,
+// sharing cookies and extracted variables between the steps.
+type HTTPFlowChecker struct {
+	BaseChecker
+	scheme    string
+	steps     []FlowStep
+	transport *http.Transport
+}
+
+func NewHTTPFlowChecker() *HTTPFlowChecker {
+	return &HTTPFlowChecker{
+		BaseChecker: NewBaseChecker(TimeoutBounds{
+			Min:     httpFlowMinTimeout,
+			Max:     httpFlowMaxTimeout,
+			Default: httpFlowDefaultTimeout,
+		}),
+		scheme:    "http",
+		transport: newHTTPTransport(&net.Dialer{}),
+	}
+}
+
+func (c *HTTPFlowChecker) Protocol() Protocol {
+	return "HTTPFLOW"
+}
+
+func (c *HTTPFlowChecker) Configure(opts Options) error {
+	c.scheme = strings.ToLower(opts.String("scheme", "http"))
+	if c.scheme != "http" && c.scheme != "https" {
+		return fmt.Errorf("unsupported scheme %q", c.scheme)
+	}
+
+	dialer, err := newDialer(opts)
+	if err != nil {
+		return err
+	}
+	c.transport = newHTTPTransport(dialer)
+	c.transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: opts.Bool("tls_skip_verify", false)}
+
+	if err := opts.Decode("steps", &c.steps); err != nil {
+		return err
+	}
+	if len(c.steps) == 0 {
+		return errors.New("at least one step must be specified")
+	}
+
+	for i := range c.steps {
+		step := &c.steps[i]
+		if step.Name == "" {
+			step.Name = strconv.Itoa(i + 1)
+		}
+		if step.Method == "" {
+			step.Method = http.MethodGet
+		}
+		if step.Expect.BodyRegex != "" {
+			re, err := regexp.Compile(step.Expect.BodyRegex)
+			if err != nil {
+				return fmt.Errorf("step %s: invalid body_regex: %w", step.Name, err)
+			}
+			step.Expect.re = re
+		}
+		for j := range step.Extract {
+			extract := &step.Extract[j]
+			if extract.Var == "" {
+				return fmt.Errorf("step %s: extract requires var", step.Name)
+			}
+			if extract.Regex != "" {
+				re, err := regexp.Compile(extract.Regex)
+				if err != nil {
+					return fmt.Errorf("step %s: invalid extract regex: %w", step.Name, err)
+				}
+				extract.re = re
+			}
+		}
+	}
+	return nil
+}
+
+func (c *HTTPFlowChecker) Check(ctx context.Context, hosts []string, port string) []HostCheckResult {
+	return c.BaseChecker.Check(ctx, hosts, port, c.checkFlow)
+}
+
+func (c *HTTPFlowChecker) checkFlow(ctx context.Context, host string, port string) (map[string]interface{}, error) {
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cookie jar: %w", err)
+	}
+	client := &http.Client{
+		Transport: c.transport,
+		Jar:       jar,
+	}
+
+	baseURL := fmt.Sprintf("%s://%s", c.scheme, net.JoinHostPort(host, port))
+	vars := map[string]string{
+		"host": host,
+		"port": port,
+	}
+
+	results := make([]FlowStepResult, 0, len(c.steps))
+	metadata := map[string]interface{}{}
+
+	for _, step := range c.steps {
+		start := time.Now()
+		status, err := c.runStep(ctx, client, baseURL, step, vars)
+		result := FlowStepResult{
+			Name:     step.Name,
+			Status:   status,
+			Duration: time.Since(start),
+		}
+		metadata["step_"+perfDataKey(step.Name)+"_time"] = result.Duration
+
+		if err != nil {
+			result.Error = err.Error()
+			results = append(results, result)
+			metadata["steps"] = results
+			metadata["failed_step"] = step.Name
+			return metadata, fmt.Errorf("step %s failed: %w", step.Name, err)
+		}
+		results = append(results, result)
+	}
+
+	metadata["steps"] = results
+	return metadata, nil
+}
+
+func (c *HTTPFlowChecker) runStep(ctx context.Context, client *http.Client, baseURL string, step FlowStep, vars map[string]string) (int, error) {
+	var body io.Reader
+	if step.Body != "" {
+		body = strings.NewReader(expandFlowVariables(step.Body, vars))
+	}
+
+	req, err := http.NewRequestWithContext(ctx, step.Method, baseURL+expandFlowVariables(step.Path, vars), body)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create request: %w", err)
+	}
+	for name, value := range step.Headers {
+		req.Header.Set(name, expandFlowVariables(value, vars))
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("http request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, httpFlowMaxBodyBytes))
+	if err != nil {
+		return resp.StatusCode, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if err := step.Expect.verify(resp.StatusCode, respBody); err != nil {
+		return resp.StatusCode, err
+	}
+
+	for _, extract := range step.Extract {
+		value, err := extract.apply(resp, respBody, client, req)
+		if err != nil {
+			return resp.StatusCode, err
+		}
+		vars[extract.Var] = value
+	}
+	return resp.StatusCode, nil
+}
+
+func (e FlowExpect) verify(status int, body []byte) error {
+	if len(e.Status) > 0 {
+		if !slices.Contains(e.Status, status) {
+			return NewCheckError(ErrorHTTPStatus, fmt.Errorf("http status error: %d", status))
+		}
+	} else if status >= 400 {
+		return NewCheckError(ErrorHTTPStatus, fmt.Errorf("http status error: %d", status))
+	}
+
+	if e.BodyContains != "" && !strings.Contains(string(body), e.BodyContains) {
+		return NewCheckError(ErrorAssertion, fmt.Errorf("response does not contain %q", e.BodyContains))
+	}
+	if e.re != nil && !e.re.Match(body) {
+		return NewCheckError(ErrorAssertion, fmt.Errorf("response does not match %s", e.re))
+	}
+	return nil
+}
+
+func (e FlowExtract) apply(resp *http.Response, body []byte, client *http.Client, req *http.Request) (string, error) {
+	switch {
+	case e.JSONPath != "":
+		// Numbers are kept as written, so IDs are not turned into floats
+		decoder := json.NewDecoder(bytes.NewReader(body))
+		decoder.UseNumber()
+		var doc interface{}
+		if err := decoder.Decode(&doc); err != nil {
+			return "", fmt.Errorf("extract %s: response is not json: %w", e.Var, err)
+		}
+		value, err := evalJSONPath(doc, e.JSONPath)
+		if err != nil {
+			return "", fmt.Errorf("extract %s: %w", e.Var, err)
+		}
+		return value, nil
+	case e.re != nil:
+		match := e.re.FindSubmatch(body)
+		if match == nil {
+			return "", NewCheckError(ErrorAssertion, fmt.Errorf("extract %s: no match for %s", e.Var, e.Regex))
+		}
+		if len(match) > 1 {
+			return string(match[1]), nil
+		}
+		return string(match[0]), nil
+	case e.Header != "":
+		value := resp.Header.Get(e.Header)
+		if value == "" {
+			return "", NewCheckError(ErrorAssertion, fmt.Errorf("extract %s: header %s not present", e.Var, e.Header))
+		}
+		return value, nil
+	case e.Cookie != "":
+		for _, cookie := range client.Jar.Cookies(req.URL) {
+			if cookie.Name == e.Cookie {
+				return cookie.Value, nil
+			}
+		}
+		return "", NewCheckError(ErrorAssertion, fmt.Errorf("extract %s: cookie %s not set", e.Var, e.Cookie))
+	}
+	return "", fmt.Errorf("extract %s: one of jsonpath, regex, header or cookie is required", e.Var)
+}
+
+func expandFlowVariables(s string, vars map[string]string) string {
+	return flowVariablePattern.ReplaceAllStringFunc(s, func(match string) string {
+		name := flowVariablePattern.FindStringSubmatch(match)[1]
+		if value, ok := vars[name]; ok {
+			return value
+		}
+		return match
+	})
+}
+
+// evalJSONPath supports the dotted subset of JSONPath: $.a.b[0].c
+func evalJSONPath(doc interface{}, path string) (string, error) {
+	path = strings.TrimPrefix(strings.TrimPrefix(path, "$"), ".")
+	current := doc
+
+	for _, segment := range strings.Split(path, ".") {
+		if segment == "" {
+			continue
+		}
+		name, indexes, _ := strings.Cut(segment, "[")
+		if name != "" {
+			obj, ok := current.(map[string]interface{})
+			if !ok {
+				return "", fmt.Errorf("%s: not an object", name)
+			}
+			if current, ok = obj[name]; !ok {
+				return "", fmt.Errorf("%s: not found", name)
+			}
+		}
+		for indexes != "" {
+			var index string
+			index, indexes, _ = strings.Cut(indexes, "]")
+			indexes = strings.TrimPrefix(indexes, "[")
+			i, err := strconv.Atoi(index)
+			if err != nil {
+				return "", fmt.Errorf("invalid index %q", index)
+			}
+			arr, ok := current.([]interface{})
+			if !ok || i < 0 || i >= len(arr) {
+				return "", fmt.Errorf("index %d out of range", i)
+			}
+			current = arr[i]
+		}
+	}
+
+	switch v := current.(type) {
+	case string:
+		return v, nil
+	case json.Number:
+		return v.String(), nil
+	case nil:
+		return "", errors.New("value is null")
+	case map[string]interface{}, []interface{}:
+		data, err := json.Marshal(v)
+		return string(data), err
+	default:
+		return fmt.Sprint(v), nil
+	}
+}
+
+func init() {
+	RegisterChecker("HTTPFLOW", func() Checker { return NewHTTPFlowChecker() })
+}
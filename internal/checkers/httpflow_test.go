@@ -0,0 +1,62 @@
+// Copyright (C) 2025 Jeff Rose
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package checkers
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHTTPFlowReusesNumericID(t *testing.T) {
+	var requested string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/orders":
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"order":{"id":12345678,"ref":9007199254740993}}`))
+		default:
+			requested = r.URL.Path
+		}
+	}))
+	defer server.Close()
+
+	host, port, _ := net.SplitHostPort(server.Listener.Addr().String())
+	checker := NewHTTPFlowChecker()
+	err := checker.Configure(Options{"steps": []interface{}{
+		map[string]interface{}{
+			"name": "create",
+			"path": "/orders",
+			"extract": []interface{}{
+				map[string]interface{}{"var": "id", "jsonpath": "$.order.id"},
+				map[string]interface{}{"var": "ref", "jsonpath": "$.order.ref"},
+			},
+		},
+		map[string]interface{}{"name": "fetch", "path": "/orders/{{id}}/{{ref}}"},
+	}})
+	if err != nil {
+		t.Fatalf("Configure: %v", err)
+	}
+
+	if _, err := checker.checkFlow(context.Background(), host, port); err != nil {
+		t.Fatalf("checkFlow: %v", err)
+	}
+	if want := "/orders/12345678/9007199254740993"; requested != want {
+		t.Errorf("second step requested %q, want %q", requested, want)
+	}
+}
@@ -19,7 +19,9 @@ import (
 	"context"
 	"crypto/tls"
 	"fmt"
+	"net"
 	"net/http"
+	"strings"
 	"sync"
 	"time"
 )
@@ -37,76 +39,130 @@ type CertInfo struct {
 
 type HTTPSChecker struct {
 	BaseChecker
-	client     *http.Client
-	mu         sync.RWMutex
-	verifyCert bool
+	client      *http.Client
+	mu          sync.RWMutex
+	verifyCert  bool
+	httpVersion string
+	content     *contentWatch
+	dialer      Dialer
+
+	expectClosed bool
 }
 
 func NewHTTPSChecker() *HTTPSChecker {
-	return &HTTPSChecker{
+	c := &HTTPSChecker{
 		BaseChecker: NewBaseChecker(TimeoutBounds{
 			Min:     httpsMinTimeout,
 			Max:     httpsMaxTimeout,
 			Default: httpsDefaultTimeout,
 		}),
-		client: &http.Client{
-			Timeout: httpsDefaultTimeout,
-			Transport: &http.Transport{
-				TLSClientConfig: &tls.Config{},
-			},
-		},
+		dialer: &net.Dialer{},
 	}
+	c.client = c.newClient()
+	return c
 }
 
 func (c *HTTPSChecker) Protocol() Protocol {
 	return "HTTPS"
 }
 
+func (c *HTTPSChecker) Configure(opts Options) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.verifyCert = opts.Bool("verify_cert", false)
+	c.httpVersion = strings.ToLower(opts.String("http_version", ""))
+	switch c.httpVersion {
+	case "", "1.1", "h2":
+	default:
+		return fmt.Errorf("unsupported http_version %q, expected 1.1 or h2", c.httpVersion)
+	}
+
+	closed, err := expectClosed(opts)
+	if err != nil {
+		return err
+	}
+	content, err := newContentWatch(opts)
+	if err != nil {
+		return err
+	}
+	if c.dialer, err = newDialer(opts); err != nil {
+		return err
+	}
+	c.expectClosed = closed
+	c.content = content
+	c.client = c.newClient()
+	return nil
+}
+
+func (c *HTTPSChecker) newClient() *http.Client {
+	transport := newHTTPTransport(c.dialer)
+	transport.TLSClientConfig = &tls.Config{
+		InsecureSkipVerify: !c.verifyCert,
+	}
+	// A custom TLS config disables HTTP/2 unless it is explicitly requested,
+	// so h2 has to be offered over ALPN here.
+	if c.httpVersion == "h2" {
+		transport.ForceAttemptHTTP2 = true
+	}
+
+	return &http.Client{
+		Timeout:   httpsDefaultTimeout,
+		Transport: transport,
+	}
+}
+
 func (c *HTTPSChecker) Check(ctx context.Context, hosts []string, port string) []HostCheckResult {
 	return c.BaseChecker.Check(ctx, hosts, port, c.checkHTTPS)
 }
 
 func (c *HTTPSChecker) checkHTTPS(ctx context.Context, host string, port string) (map[string]interface{}, error) {
-	url := fmt.Sprintf("https://%s:%s", host, port)
+	url := "https://" + net.JoinHostPort(host, port)
 	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
-	client := c.client
-	if !c.verifyCert {
-		client = &http.Client{
-			Timeout: c.GetTimeout(),
-			Transport: &http.Transport{
-				TLSClientConfig: &tls.Config{
-					InsecureSkipVerify: true,
-				},
-			},
-		}
-	}
+	c.mu.RLock()
+	client, content, closed := c.client, c.content, c.expectClosed
+	c.mu.RUnlock()
 
 	resp, err := client.Do(req)
+	if closed {
+		return verifyHTTPClosed(resp, err)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("https request failed: %w", err)
 	}
 	defer resp.Body.Close()
 
+	metadata := map[string]interface{}{
+		"protocol": resp.Proto,
+	}
 	if resp.StatusCode >= 400 {
-		return nil, fmt.Errorf("https status error: %d", resp.StatusCode)
+		return metadata, NewCheckError(ErrorHTTPStatus, fmt.Errorf("https status error: %d", resp.StatusCode))
 	}
 
 	// Collect cert info if available
-	var metadata map[string]interface{}
-	if resp.TLS != nil && len(resp.TLS.PeerCertificates) > 0 {
-		cert := resp.TLS.PeerCertificates[0]
-		metadata = map[string]interface{}{
-			"cert_info": &CertInfo{
+	if resp.TLS != nil {
+		metadata["alpn"] = resp.TLS.NegotiatedProtocol
+		if len(resp.TLS.PeerCertificates) > 0 {
+			cert := resp.TLS.PeerCertificates[0]
+			metadata["cert_info"] = &CertInfo{
 				ExpiresAt: cert.NotAfter,
 				IssuedBy:  cert.Issuer.CommonName,
-			},
+			}
 		}
 	}
 
+	if err := verifyHTTPVersion(c.httpVersion, resp); err != nil {
+		return metadata, err
+	}
+	if content != nil {
+		if err := content.verify(stateKey(ctx, host, port), resp.Body, metadata); err != nil {
+			return metadata, err
+		}
+	}
 	return metadata, nil
 }
 
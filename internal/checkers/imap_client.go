@@ -0,0 +1,152 @@
+// Copyright (C) 2025 Jeff Rose
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package checkers
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// imapClient implements the handful of IMAP4rev1 commands needed to find and
+// remove a probe message. It is not a general purpose client.
+type imapClient struct {
+	conn   net.Conn
+	reader *bufio.Reader
+	tag    int
+}
+
+func newIMAPClient(conn net.Conn) (*imapClient, error) {
+	c := &imapClient{
+		conn:   conn,
+		reader: bufio.NewReader(conn),
+	}
+
+	greeting, err := c.readLine()
+	if err != nil {
+		return nil, fmt.Errorf("imap greeting failed: %w", err)
+	}
+	if !strings.HasPrefix(greeting, "* OK") && !strings.HasPrefix(greeting, "* PREAUTH") {
+		return nil, fmt.Errorf("unexpected imap greeting: %s", greeting)
+	}
+	return c, nil
+}
+
+func (c *imapClient) Login(username, password string) error {
+	_, err := c.command("LOGIN %s %s", quoteIMAP(username), quoteIMAP(password))
+	return err
+}
+
+func (c *imapClient) Select(mailbox string) error {
+	_, err := c.command("SELECT %s", quoteIMAP(mailbox))
+	return err
+}
+
+// SearchHeader returns the UIDs of messages whose header field contains value.
+func (c *imapClient) SearchHeader(field, value string) ([]uint32, error) {
+	lines, err := c.command("UID SEARCH HEADER %s %s", quoteIMAP(field), quoteIMAP(value))
+	if err != nil {
+		return nil, err
+	}
+
+	var uids []uint32
+	for _, line := range lines {
+		if !strings.HasPrefix(line, "* SEARCH") {
+			continue
+		}
+		for _, field := range strings.Fields(strings.TrimPrefix(line, "* SEARCH")) {
+			uid, err := strconv.ParseUint(field, 10, 32)
+			if err != nil {
+				return nil, fmt.Errorf("invalid uid in search response: %q", field)
+			}
+			uids = append(uids, uint32(uid))
+		}
+	}
+	return uids, nil
+}
+
+func (c *imapClient) Delete(uids []uint32) error {
+	if len(uids) == 0 {
+		return nil
+	}
+
+	set := make([]string, len(uids))
+	for i, uid := range uids {
+		set[i] = strconv.FormatUint(uint64(uid), 10)
+	}
+
+	if _, err := c.command("UID STORE %s +FLAGS.SILENT (\\Deleted)", strings.Join(set, ",")); err != nil {
+		return err
+	}
+	_, err := c.command("EXPUNGE")
+	return err
+}
+
+func (c *imapClient) Logout() error {
+	_, err := c.command("LOGOUT")
+	return err
+}
+
+func (c *imapClient) Close() error {
+	return c.conn.Close()
+}
+
+// command sends a tagged command and returns the untagged responses that
+// preceded the tagged completion.
+func (c *imapClient) command(format string, args ...interface{}) ([]string, error) {
+	c.tag++
+	tag := fmt.Sprintf("a%d", c.tag)
+
+	if _, err := fmt.Fprintf(c.conn, "%s %s\r\n", tag, fmt.Sprintf(format, args...)); err != nil {
+		return nil, err
+	}
+
+	var untagged []string
+	for {
+		line, err := c.readLine()
+		if err != nil {
+			return nil, err
+		}
+
+		if !strings.HasPrefix(line, tag+" ") {
+			untagged = append(untagged, line)
+			continue
+		}
+
+		status := strings.TrimPrefix(line, tag+" ")
+		if !strings.HasPrefix(status, "OK") {
+			return nil, errors.New("imap command failed: " + status)
+		}
+		return untagged, nil
+	}
+}
+
+func (c *imapClient) readLine() (string, error) {
+	line, err := c.reader.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+func quoteIMAP(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	return `"` + s + `"`
+}
@@ -0,0 +1,45 @@
+// Copyright (C) 2025 Jeff Rose
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package checkers
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Local checkers read the state of the machine CheckMate runs on, so the only
+// accepted host is the local one.
+
+func isLocalHost(host string) bool {
+	switch host {
+	case "localhost", "127.0.0.1", "::1":
+		return true
+	}
+	hostname, err := os.Hostname()
+	return err == nil && strings.EqualFold(host, hostname)
+}
+
+func localCheck(ctx context.Context, host string, checkFn func() (map[string]interface{}, error)) (map[string]interface{}, error) {
+	if !isLocalHost(host) {
+		return nil, fmt.Errorf("host %q is not local, local checks only run against localhost", host)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return checkFn()
+}
@@ -0,0 +1,411 @@
+// Copyright (C) 2025 Jeff Rose
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package checkers
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+const (
+	localMinTimeout     = 1 * time.Second
+	localMaxTimeout     = 10 * time.Second
+	localDefaultTimeout = 5 * time.Second
+
+	procPath = "/proc"
+)
+
+var localTimeoutBounds = TimeoutBounds{
+	Min:     localMinTimeout,
+	Max:     localMaxTimeout,
+	Default: localDefaultTimeout,
+}
+
+// DiskUsage describes the block and inode usage of a single mount.
+type DiskUsage struct {
+	Mount            string
+	TotalBytes       uint64
+	FreeBytes        uint64
+	UsedPercent      float64
+	InodesTotal      uint64
+	InodesFree       uint64
+	InodeUsedPercent float64
+}
+
+type DiskChecker struct {
+	BaseChecker
+	mounts          []string
+	maxUsedPercent  float64
+	maxInodePercent float64
+}
+
+func NewDiskChecker() *DiskChecker {
+	return &DiskChecker{
+		BaseChecker:     NewBaseChecker(localTimeoutBounds),
+		mounts:          []string{"/"},
+		maxUsedPercent:  90,
+		maxInodePercent: 90,
+	}
+}
+
+func (c *DiskChecker) Protocol() Protocol {
+	return "DISK"
+}
+
+func (c *DiskChecker) Configure(opts Options) error {
+	if mounts := opts.StringSlice("mounts"); len(mounts) > 0 {
+		c.mounts = mounts
+	}
+
+	var err error
+	if c.maxUsedPercent, err = opts.Float("max_used_percent", 90); err != nil {
+		return err
+	}
+	if c.maxInodePercent, err = opts.Float("max_inode_percent", 90); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (c *DiskChecker) Check(ctx context.Context, hosts []string, port string) []HostCheckResult {
+	return c.BaseChecker.Check(ctx, hosts, port, func(ctx context.Context, host string, _ string) (map[string]interface{}, error) {
+		return localCheck(ctx, host, c.checkDisk)
+	})
+}
+
+func (c *DiskChecker) checkDisk() (map[string]interface{}, error) {
+	usages := make([]DiskUsage, 0, len(c.mounts))
+	var worstUsed, worstInode float64
+	var problems []string
+
+	for _, mount := range c.mounts {
+		var stat syscall.Statfs_t
+		if err := syscall.Statfs(mount, &stat); err != nil {
+			return nil, fmt.Errorf("statfs %s failed: %w", mount, err)
+		}
+
+		usage := DiskUsage{
+			Mount:       mount,
+			TotalBytes:  stat.Blocks * uint64(stat.Bsize),
+			FreeBytes:   stat.Bavail * uint64(stat.Bsize),
+			InodesTotal: stat.Files,
+			InodesFree:  stat.Ffree,
+		}
+		if stat.Blocks > 0 {
+			usage.UsedPercent = 100 * float64(stat.Blocks-stat.Bavail) / float64(stat.Blocks)
+		}
+		if stat.Files > 0 {
+			usage.InodeUsedPercent = 100 * float64(stat.Files-stat.Ffree) / float64(stat.Files)
+		}
+		usages = append(usages, usage)
+
+		worstUsed = max(worstUsed, usage.UsedPercent)
+		worstInode = max(worstInode, usage.InodeUsedPercent)
+
+		if c.maxUsedPercent > 0 && usage.UsedPercent > c.maxUsedPercent {
+			problems = append(problems, fmt.Sprintf("%s is %.1f%% full", mount, usage.UsedPercent))
+		}
+		if c.maxInodePercent > 0 && usage.InodeUsedPercent > c.maxInodePercent {
+			problems = append(problems, fmt.Sprintf("%s uses %.1f%% of inodes", mount, usage.InodeUsedPercent))
+		}
+	}
+
+	metadata := map[string]interface{}{
+		"mounts":             usages,
+		"disk_used_percent":  worstUsed,
+		"inode_used_percent": worstInode,
+	}
+	if len(problems) > 0 {
+		return metadata, NewCheckError(ErrorAssertion, errors.New(strings.Join(problems, ", ")))
+	}
+	return metadata, nil
+}
+
+type MemoryChecker struct {
+	BaseChecker
+	maxMemoryPercent float64
+	maxSwapPercent   float64
+}
+
+func NewMemoryChecker() *MemoryChecker {
+	return &MemoryChecker{
+		BaseChecker:      NewBaseChecker(localTimeoutBounds),
+		maxMemoryPercent: 90,
+	}
+}
+
+func (c *MemoryChecker) Protocol() Protocol {
+	return "MEMORY"
+}
+
+func (c *MemoryChecker) Configure(opts Options) error {
+	var err error
+	if c.maxMemoryPercent, err = opts.Float("max_memory_percent", 90); err != nil {
+		return err
+	}
+	if c.maxSwapPercent, err = opts.Float("max_swap_percent", 0); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (c *MemoryChecker) Check(ctx context.Context, hosts []string, port string) []HostCheckResult {
+	return c.BaseChecker.Check(ctx, hosts, port, func(ctx context.Context, host string, _ string) (map[string]interface{}, error) {
+		return localCheck(ctx, host, c.checkMemory)
+	})
+}
+
+func (c *MemoryChecker) checkMemory() (map[string]interface{}, error) {
+	info, err := readMeminfo()
+	if err != nil {
+		return nil, err
+	}
+
+	memTotal, memAvailable := info["MemTotal"], info["MemAvailable"]
+	swapTotal, swapFree := info["SwapTotal"], info["SwapFree"]
+	if memTotal == 0 {
+		return nil, errors.New("MemTotal missing from meminfo")
+	}
+
+	memoryPercent := 100 * float64(memTotal-memAvailable) / float64(memTotal)
+	var swapPercent float64
+	if swapTotal > 0 {
+		swapPercent = 100 * float64(swapTotal-swapFree) / float64(swapTotal)
+	}
+
+	metadata := map[string]interface{}{
+		"memory_used_percent":    memoryPercent,
+		"memory_available_bytes": memAvailable,
+		"swap_used_percent":      swapPercent,
+	}
+
+	var problems []string
+	if c.maxMemoryPercent > 0 && memoryPercent > c.maxMemoryPercent {
+		problems = append(problems, fmt.Sprintf("memory is %.1f%% used", memoryPercent))
+	}
+	if c.maxSwapPercent > 0 && swapPercent > c.maxSwapPercent {
+		problems = append(problems, fmt.Sprintf("swap is %.1f%% used", swapPercent))
+	}
+	if len(problems) > 0 {
+		return metadata, NewCheckError(ErrorAssertion, errors.New(strings.Join(problems, ", ")))
+	}
+	return metadata, nil
+}
+
+// readMeminfo returns /proc/meminfo values in bytes.
+func readMeminfo() (map[string]uint64, error) {
+	f, err := os.Open(filepath.Join(procPath, "meminfo"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read meminfo: %w", err)
+	}
+	defer f.Close()
+
+	info := make(map[string]uint64)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), ":")
+		if !ok {
+			continue
+		}
+		fields := strings.Fields(value)
+		if len(fields) == 0 {
+			continue
+		}
+		n, err := strconv.ParseUint(fields[0], 10, 64)
+		if err != nil {
+			continue
+		}
+		if len(fields) > 1 && fields[1] == "kB" {
+			n *= 1024
+		}
+		info[key] = n
+	}
+	return info, scanner.Err()
+}
+
+type LoadChecker struct {
+	BaseChecker
+	maxLoad [3]float64
+	perCPU  bool
+}
+
+func NewLoadChecker() *LoadChecker {
+	return &LoadChecker{
+		BaseChecker: NewBaseChecker(localTimeoutBounds),
+	}
+}
+
+func (c *LoadChecker) Protocol() Protocol {
+	return "LOAD"
+}
+
+func (c *LoadChecker) Configure(opts Options) error {
+	for i, key := range []string{"max_load1", "max_load5", "max_load15"} {
+		value, err := opts.Float(key, 0)
+		if err != nil {
+			return err
+		}
+		c.maxLoad[i] = value
+	}
+	c.perCPU = opts.Bool("per_cpu", false)
+	return nil
+}
+
+func (c *LoadChecker) Check(ctx context.Context, hosts []string, port string) []HostCheckResult {
+	return c.BaseChecker.Check(ctx, hosts, port, func(ctx context.Context, host string, _ string) (map[string]interface{}, error) {
+		return localCheck(ctx, host, c.checkLoad)
+	})
+}
+
+func (c *LoadChecker) checkLoad() (map[string]interface{}, error) {
+	data, err := os.ReadFile(filepath.Join(procPath, "loadavg"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read loadavg: %w", err)
+	}
+
+	fields := strings.Fields(string(data))
+	if len(fields) < 3 {
+		return nil, fmt.Errorf("unexpected loadavg format: %q", data)
+	}
+
+	cpus := runtime.NumCPU()
+	names := []string{"load1", "load5", "load15"}
+	metadata := map[string]interface{}{
+		"cpus": cpus,
+	}
+
+	var problems []string
+	for i, name := range names {
+		load, err := strconv.ParseFloat(fields[i], 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s value %q", name, fields[i])
+		}
+		if c.perCPU {
+			load /= float64(cpus)
+		}
+		metadata[name] = load
+
+		if c.maxLoad[i] > 0 && load > c.maxLoad[i] {
+			problems = append(problems, fmt.Sprintf("%s is %.2f (max %.2f)", name, load, c.maxLoad[i]))
+		}
+	}
+
+	if len(problems) > 0 {
+		return metadata, NewCheckError(ErrorAssertion, errors.New(strings.Join(problems, ", ")))
+	}
+	return metadata, nil
+}
+
+type ProcessChecker struct {
+	BaseChecker
+	name     string
+	minCount int
+	maxCount int
+}
+
+func NewProcessChecker() *ProcessChecker {
+	return &ProcessChecker{
+		BaseChecker: NewBaseChecker(localTimeoutBounds),
+		minCount:    1,
+	}
+}
+
+func (c *ProcessChecker) Protocol() Protocol {
+	return "PROCESS"
+}
+
+func (c *ProcessChecker) Configure(opts Options) error {
+	c.name = opts.String("name", "")
+	if c.name == "" {
+		return errors.New("name must be specified")
+	}
+
+	var err error
+	if c.minCount, err = opts.Int("min_count", 1); err != nil {
+		return err
+	}
+	if c.maxCount, err = opts.Int("max_count", 0); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (c *ProcessChecker) Check(ctx context.Context, hosts []string, port string) []HostCheckResult {
+	return c.BaseChecker.Check(ctx, hosts, port, func(ctx context.Context, host string, _ string) (map[string]interface{}, error) {
+		return localCheck(ctx, host, c.checkProcess)
+	})
+}
+
+func (c *ProcessChecker) checkProcess() (map[string]interface{}, error) {
+	entries, err := os.ReadDir(procPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list processes: %w", err)
+	}
+
+	count := 0
+	for _, entry := range entries {
+		if _, err := strconv.Atoi(entry.Name()); err != nil {
+			continue
+		}
+		if processMatches(filepath.Join(procPath, entry.Name()), c.name) {
+			count++
+		}
+	}
+
+	metadata := map[string]interface{}{
+		"process_count": count,
+	}
+	if count < c.minCount {
+		return metadata, NewCheckError(ErrorAssertion, fmt.Errorf("found %d %s processes, expected at least %d", count, c.name, c.minCount))
+	}
+	if c.maxCount > 0 && count > c.maxCount {
+		return metadata, NewCheckError(ErrorAssertion, fmt.Errorf("found %d %s processes, expected at most %d", count, c.name, c.maxCount))
+	}
+	return metadata, nil
+}
+
+// processMatches compares name with the process comm, which the kernel
+// truncates to 15 characters, and with the base name of argv[0].
+func processMatches(dir, name string) bool {
+	if comm, err := os.ReadFile(filepath.Join(dir, "comm")); err == nil {
+		if strings.TrimSpace(string(comm)) == name {
+			return true
+		}
+	}
+
+	cmdline, err := os.ReadFile(filepath.Join(dir, "cmdline"))
+	if err != nil || len(cmdline) == 0 {
+		return false
+	}
+	argv0, _, _ := strings.Cut(string(cmdline), "\x00")
+	return filepath.Base(argv0) == name
+}
+
+func init() {
+	RegisterChecker("DISK", func() Checker { return NewDiskChecker() })
+	RegisterChecker("MEMORY", func() Checker { return NewMemoryChecker() })
+	RegisterChecker("LOAD", func() Checker { return NewLoadChecker() })
+	RegisterChecker("PROCESS", func() Checker { return NewProcessChecker() })
+}
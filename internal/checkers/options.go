@@ -0,0 +1,144 @@
+// Copyright (C) 2025 Jeff Rose
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package checkers
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Options holds protocol specific settings from a check's `options` block.
+type Options map[string]interface{}
+
+// Configurable is implemented by checkers that accept per-check options.
+type Configurable interface {
+	Configure(opts Options) error
+}
+
+func (o Options) String(key, def string) string {
+	v, ok := o[key]
+	if !ok || v == nil {
+		return def
+	}
+	return fmt.Sprint(v)
+}
+
+func (o Options) Bool(key string, def bool) bool {
+	switch v := o[key].(type) {
+	case bool:
+		return v
+	case string:
+		if b, err := strconv.ParseBool(v); err == nil {
+			return b
+		}
+	}
+	return def
+}
+
+func (o Options) Int(key string, def int) (int, error) {
+	switch v := o[key].(type) {
+	case nil:
+		return def, nil
+	case int:
+		return v, nil
+	case float64:
+		return int(v), nil
+	case string:
+		i, err := strconv.Atoi(v)
+		if err != nil {
+			return 0, fmt.Errorf("option %s: %w", key, err)
+		}
+		return i, nil
+	default:
+		return 0, fmt.Errorf("option %s: expected integer, got %T", key, v)
+	}
+}
+
+func (o Options) Float(key string, def float64) (float64, error) {
+	switch v := o[key].(type) {
+	case nil:
+		return def, nil
+	case int:
+		return float64(v), nil
+	case float64:
+		return v, nil
+	case string:
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return 0, fmt.Errorf("option %s: %w", key, err)
+		}
+		return f, nil
+	default:
+		return 0, fmt.Errorf("option %s: expected number, got %T", key, v)
+	}
+}
+
+func (o Options) Duration(key string, def time.Duration) (time.Duration, error) {
+	switch v := o[key].(type) {
+	case nil:
+		return def, nil
+	case int:
+		return time.Duration(v) * time.Second, nil
+	case string:
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return 0, fmt.Errorf("option %s: %w", key, err)
+		}
+		return d, nil
+	default:
+		return 0, fmt.Errorf("option %s: expected duration, got %T", key, v)
+	}
+}
+
+// StringSlice returns a list option. A single value, such as expected: 443,
+// is treated as a list of one.
+func (o Options) StringSlice(key string) []string {
+	switch v := o[key].(type) {
+	case []interface{}:
+		values := make([]string, 0, len(v))
+		for _, item := range v {
+			values = append(values, fmt.Sprint(item))
+		}
+		return values
+	case []string:
+		return v
+	case string:
+		return []string{v}
+	case int, int64, uint64, float64, bool:
+		return []string{fmt.Sprint(v)}
+	}
+	return nil
+}
+
+// Decode unmarshals a nested option, such as a list of steps, into out using
+// the out type's yaml tags.
+func (o Options) Decode(key string, out interface{}) error {
+	v, ok := o[key]
+	if !ok {
+		return nil
+	}
+	data, err := yaml.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("option %s: %w", key, err)
+	}
+	if err := yaml.UnmarshalStrict(data, out); err != nil {
+		return fmt.Errorf("option %s: %w", key, err)
+	}
+	return nil
+}
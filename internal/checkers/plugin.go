@@ -0,0 +1,479 @@
+// Copyright (C) 2025 Jeff Rose
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package checkers
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	pluginDescribeTimeout = 10 * time.Second
+	pluginMaxLineBytes    = 1 << 20
+)
+
+// Plugins are long-lived executables that speak JSON lines over stdin and
+// stdout. At startup CheckMate sends
+//
+//	{"type":"describe"}
+//
+// and the plugin answers with its protocol name and options:
+//
+//	{"type":"describe","protocol":"REDIS","options":[{"name":"password","required":false}],
+//	 "timeout":{"min":"1s","max":"30s","default":"5s"}}
+//
+// Each host check is then sent as a request with an id, and may be answered
+// in any order:
+//
+//	{"type":"check","id":7,"host":"db1","port":"6379","timeout_ms":5000,"options":{...}}
+//	{"type":"result","id":7,"success":true,"metadata":{"role":"master"}}
+//
+// A failed check sets "success":false and an "error" message, optionally
+// with an "error_class"; unknown classes are reported as protocol_error.
+
+type pluginMessage struct {
+	Type      string                 `json:"type"`
+	ID        uint64                 `json:"id,omitempty"`
+	Host      string                 `json:"host,omitempty"`
+	Port      string                 `json:"port,omitempty"`
+	TimeoutMS int64                  `json:"timeout_ms,omitempty"`
+	Options   map[string]interface{} `json:"options,omitempty"`
+	Success   bool                   `json:"success,omitempty"`
+	Error     string                 `json:"error,omitempty"`
+	Metadata  map[string]interface{} `json:"metadata,omitempty"`
+	// ErrorClass optionally classifies a failed result
+	ErrorClass ErrorClass `json:"error_class,omitempty"`
+}
+
+type pluginDescription struct {
+	Type     string         `json:"type"`
+	Protocol string         `json:"protocol"`
+	Options  []PluginOption `json:"options"`
+	Timeout  struct {
+		Min     string `json:"min"`
+		Max     string `json:"max"`
+		Default string `json:"default"`
+	} `json:"timeout"`
+}
+
+// PluginOption is an option a plugin accepts in a check's `options` block.
+type PluginOption struct {
+	Name        string `json:"name"`
+	Required    bool   `json:"required"`
+	Description string `json:"description,omitempty"`
+}
+
+// LoadPlugins starts every executable in dir, asks it to describe itself
+// and registers a checker for the protocol it reports. The processes run
+// until ctx is cancelled.
+func LoadPlugins(ctx context.Context, dir string) ([]Protocol, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read plugins directory: %w", err)
+	}
+
+	var protocols []Protocol
+	for _, entry := range entries {
+		if entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil || info.Mode().Perm()&0o111 == 0 {
+			continue
+		}
+
+		p := &plugin{ctx: ctx, path: filepath.Join(dir, entry.Name())}
+		if err := p.describe(); err != nil {
+			return nil, fmt.Errorf("plugin %s: %w", entry.Name(), err)
+		}
+		if p.protocol.IsValid() {
+			p.stop()
+			return nil, fmt.Errorf("plugin %s: protocol %s is already registered", entry.Name(), p.protocol)
+		}
+		RegisterChecker(p.protocol, func() Checker { return newPluginChecker(p) })
+		protocols = append(protocols, p.protocol)
+	}
+	return protocols, nil
+}
+
+// IsPlugin reports whether protocol is provided by a loaded plugin.
+func IsPlugin(protocol Protocol) bool {
+	checker, err := NewChecker(protocol)
+	if err != nil {
+		return false
+	}
+	_, ok := checker.(*PluginChecker)
+	return ok
+}
+
+// plugin is a running plugin process shared by every check of its protocol.
+// Requests are multiplexed by id; a process that exits is restarted on the
+// next request.
+type plugin struct {
+	ctx      context.Context
+	path     string
+	protocol Protocol
+	options  []PluginOption
+	bounds   TimeoutBounds
+
+	mu      sync.Mutex
+	cmd     *exec.Cmd
+	stdin   io.WriteCloser
+	nextID  uint64
+	pending map[uint64]chan pluginMessage
+	// exited is closed when the current process has stopped
+	exited chan struct{}
+}
+
+func (p *plugin) describe() error {
+	ctx, cancel := context.WithTimeout(p.ctx, pluginDescribeTimeout)
+	defer cancel()
+
+	cmd, stdin, scanner, err := p.launch()
+	if err != nil {
+		return err
+	}
+
+	lines := make(chan []byte, 1)
+	go func() {
+		if scanner.Scan() {
+			lines <- append([]byte(nil), scanner.Bytes()...)
+		}
+		close(lines)
+	}()
+
+	var desc pluginDescription
+	err = writePluginMessage(stdin, pluginMessage{Type: "describe"})
+	if err == nil {
+		select {
+		case line, ok := <-lines:
+			if !ok {
+				err = errors.New("exited without describing itself")
+			} else if err = json.Unmarshal(line, &desc); err != nil {
+				err = fmt.Errorf("invalid describe response: %w", err)
+			}
+		case <-ctx.Done():
+			err = fmt.Errorf("describe timed out: %w", ctx.Err())
+		}
+	}
+	if err == nil {
+		err = p.applyDescription(desc)
+	}
+	if err != nil {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+		return err
+	}
+
+	p.mu.Lock()
+	p.attach(cmd, stdin, scanner)
+	p.mu.Unlock()
+	return nil
+}
+
+func (p *plugin) applyDescription(desc pluginDescription) error {
+	if desc.Type != "describe" || desc.Protocol == "" {
+		return errors.New("describe response must have type describe and a protocol")
+	}
+	p.protocol = Protocol(strings.ToUpper(desc.Protocol))
+	p.options = desc.Options
+
+	durations := []struct {
+		value string
+		out   *time.Duration
+	}{
+		{desc.Timeout.Min, &p.bounds.Min},
+		{desc.Timeout.Max, &p.bounds.Max},
+		{desc.Timeout.Default, &p.bounds.Default},
+	}
+	for _, d := range durations {
+		if d.value == "" {
+			continue
+		}
+		parsed, err := time.ParseDuration(d.value)
+		if err != nil {
+			return fmt.Errorf("invalid timeout %q: %w", d.value, err)
+		}
+		*d.out = parsed
+	}
+	return nil
+}
+
+func (p *plugin) launch() (*exec.Cmd, io.WriteCloser, *bufio.Scanner, error) {
+	cmd := exec.Command(p.path)
+	cmd.Stderr = os.Stderr
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to start plugin %s: %w", p.path, err)
+	}
+	scanner := bufio.NewScanner(stdout)
+	scanner.Buffer(make([]byte, 64<<10), pluginMaxLineBytes)
+	return cmd, stdin, scanner, nil
+}
+
+// attach takes over a started process, reading its responses until it
+// exits. The caller holds p.mu.
+func (p *plugin) attach(cmd *exec.Cmd, stdin io.WriteCloser, scanner *bufio.Scanner) {
+	exited := make(chan struct{})
+	p.cmd = cmd
+	p.stdin = stdin
+	p.exited = exited
+	p.pending = make(map[uint64]chan pluginMessage)
+
+	go p.readResponses(cmd, scanner, p.pending, exited)
+	go func() {
+		select {
+		case <-p.ctx.Done():
+			// Closing stdin asks the plugin to exit; it is killed if it lingers
+			stdin.Close()
+			timer := time.NewTimer(time.Second)
+			defer timer.Stop()
+			select {
+			case <-exited:
+			case <-timer.C:
+				_ = cmd.Process.Kill()
+			}
+		case <-exited:
+		}
+	}()
+}
+
+func (p *plugin) readResponses(cmd *exec.Cmd, scanner *bufio.Scanner, pending map[uint64]chan pluginMessage, exited chan struct{}) {
+	for scanner.Scan() {
+		var msg pluginMessage
+		if err := json.Unmarshal(scanner.Bytes(), &msg); err != nil || msg.Type != "result" {
+			continue
+		}
+		p.mu.Lock()
+		ch, ok := pending[msg.ID]
+		delete(pending, msg.ID)
+		p.mu.Unlock()
+		if ok {
+			ch <- msg
+		}
+	}
+
+	_ = cmd.Wait()
+	p.mu.Lock()
+	for id, ch := range pending {
+		close(ch)
+		delete(pending, id)
+	}
+	if p.exited == exited {
+		p.cmd = nil
+		p.stdin = nil
+	}
+	p.mu.Unlock()
+	close(exited)
+}
+
+func (p *plugin) stop() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.cmd != nil {
+		p.stdin.Close()
+		_ = p.cmd.Process.Kill()
+	}
+}
+
+// check sends a check request and waits for its result.
+func (p *plugin) check(ctx context.Context, host, port string, opts map[string]interface{}) (pluginMessage, error) {
+	ch := make(chan pluginMessage, 1)
+
+	p.mu.Lock()
+	if p.cmd == nil {
+		if err := p.ctx.Err(); err != nil {
+			p.mu.Unlock()
+			return pluginMessage{}, fmt.Errorf("plugin stopped: %w", err)
+		}
+		cmd, stdin, scanner, err := p.launch()
+		if err != nil {
+			p.mu.Unlock()
+			return pluginMessage{}, err
+		}
+		p.attach(cmd, stdin, scanner)
+	}
+	p.nextID++
+	req := pluginMessage{
+		Type:    "check",
+		ID:      p.nextID,
+		Host:    host,
+		Port:    port,
+		Options: opts,
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		req.TimeoutMS = time.Until(deadline).Milliseconds()
+	}
+	pending := p.pending
+	pending[req.ID] = ch
+	err := writePluginMessage(p.stdin, req)
+	p.mu.Unlock()
+
+	if err != nil {
+		p.forget(pending, req.ID)
+		return pluginMessage{}, fmt.Errorf("failed to send check to plugin: %w", err)
+	}
+
+	select {
+	case msg, ok := <-ch:
+		if !ok {
+			return pluginMessage{}, errors.New("plugin exited before answering")
+		}
+		return msg, nil
+	case <-ctx.Done():
+		p.forget(pending, req.ID)
+		return pluginMessage{}, fmt.Errorf("plugin timed out: %w", ctx.Err())
+	}
+}
+
+func (p *plugin) forget(pending map[uint64]chan pluginMessage, id uint64) {
+	p.mu.Lock()
+	delete(pending, id)
+	p.mu.Unlock()
+}
+
+func writePluginMessage(w io.Writer, msg pluginMessage) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(data, '\n'))
+	return err
+}
+
+// PluginChecker runs checks in an out-of-process plugin.
+type PluginChecker struct {
+	BaseChecker
+	plugin  *plugin
+	options map[string]interface{}
+}
+
+func newPluginChecker(p *plugin) *PluginChecker {
+	return &PluginChecker{
+		BaseChecker: NewBaseChecker(p.bounds),
+		plugin:      p,
+	}
+}
+
+func (c *PluginChecker) Protocol() Protocol {
+	return c.plugin.protocol
+}
+
+// frameworkOptions are set by the monitor from typed check settings for
+// CheckMate's own dialer and wrappers. Plugins open their own connections,
+// so these are not passed on unless the plugin declares them itself.
+var frameworkOptions = []string{
+	"proxy", "source_ip", "interface", "verify_cert", "address_family", "resolve_all", "expect", "targets",
+}
+
+// Configure checks the options against those the plugin described, so a
+// typo fails at startup rather than on every check.
+func (c *PluginChecker) Configure(opts Options) error {
+	declared := func(key string) bool {
+		return slices.ContainsFunc(c.plugin.options, func(o PluginOption) bool { return o.Name == key })
+	}
+
+	options := make(map[string]interface{}, len(opts))
+	var unknown []string
+	for key, value := range opts {
+		switch {
+		case declared(key):
+			options[key] = jsonValue(value)
+		case !slices.Contains(frameworkOptions, key):
+			unknown = append(unknown, key)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return fmt.Errorf("unknown options %s", strings.Join(unknown, ", "))
+	}
+	for _, option := range c.plugin.options {
+		if _, ok := opts[option.Name]; option.Required && !ok {
+			return fmt.Errorf("option %s must be specified", option.Name)
+		}
+	}
+
+	c.options = options
+	return nil
+}
+
+func (c *PluginChecker) Check(ctx context.Context, hosts []string, port string) []HostCheckResult {
+	return c.BaseChecker.Check(ctx, hosts, port, c.checkPlugin)
+}
+
+func (c *PluginChecker) checkPlugin(ctx context.Context, host string, port string) (map[string]interface{}, error) {
+	result, err := c.plugin.check(ctx, host, port, c.options)
+	if err != nil {
+		return nil, err
+	}
+	if !result.Success {
+		if result.Error == "" {
+			result.Error = "plugin reported failure"
+		}
+		class := result.ErrorClass
+		if !class.IsValid() {
+			class = ErrorProtocol
+		}
+		return result.Metadata, NewCheckError(class, errors.New(result.Error))
+	}
+	return result.Metadata, nil
+}
+
+// jsonValue converts the map[interface{}]interface{} values produced by the
+// YAML decoder into types encoding/json can marshal.
+func jsonValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for key, value := range v {
+			m[fmt.Sprint(key)] = jsonValue(value)
+		}
+		return m
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for key, value := range v {
+			m[key] = jsonValue(value)
+		}
+		return m
+	case []interface{}:
+		s := make([]interface{}, len(v))
+		for i, value := range v {
+			s[i] = jsonValue(value)
+		}
+		return s
+	default:
+		return v
+	}
+}
@@ -0,0 +1,255 @@
+// Copyright (C) 2025 Jeff Rose
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package checkers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	portScanMinTimeout     = 2 * time.Second
+	portScanMaxTimeout     = 10 * time.Minute
+	portScanDefaultTimeout = 60 * time.Second
+
+	portScanDefaultConcurrency    = 100
+	portScanDefaultConnectTimeout = 1 * time.Second
+)
+
+// PortScanChecker connects to a list of ports on each host and fails when
+// the set of open ports drifts from the expected baseline, or from the
+// previous scan of the host when no baseline is configured.
+type PortScanChecker struct {
+	BaseChecker
+	ports          []int
+	expected       []int
+	concurrency    int
+	connectTimeout time.Duration
+	dialer         Dialer
+
+	mu       sync.Mutex
+	previous map[string][]int
+}
+
+func NewPortScanChecker() *PortScanChecker {
+	return &PortScanChecker{
+		BaseChecker: NewBaseChecker(TimeoutBounds{
+			Min:     portScanMinTimeout,
+			Max:     portScanMaxTimeout,
+			Default: portScanDefaultTimeout,
+		}),
+		concurrency:    portScanDefaultConcurrency,
+		connectTimeout: portScanDefaultConnectTimeout,
+		dialer:         &net.Dialer{},
+		previous:       make(map[string][]int),
+	}
+}
+
+func (c *PortScanChecker) Protocol() Protocol {
+	return "PORTSCAN"
+}
+
+func (c *PortScanChecker) Configure(opts Options) error {
+	var err error
+	if c.ports, err = parsePortList(opts.StringSlice("ports")); err != nil {
+		return fmt.Errorf("option ports: %w", err)
+	}
+	if len(c.ports) == 0 {
+		return errors.New("ports must be specified")
+	}
+
+	if _, ok := opts["expected"]; ok {
+		if c.expected, err = parsePortList(opts.StringSlice("expected")); err != nil {
+			return fmt.Errorf("option expected: %w", err)
+		}
+		// An empty list is a valid baseline: no port may be open.
+		if c.expected == nil {
+			c.expected = []int{}
+		}
+	}
+
+	if c.concurrency, err = opts.Int("concurrency", portScanDefaultConcurrency); err != nil {
+		return err
+	}
+	if c.concurrency < 1 {
+		c.concurrency = 1
+	}
+	if c.connectTimeout, err = opts.Duration("connect_timeout", portScanDefaultConnectTimeout); err != nil {
+		return err
+	}
+	c.dialer, err = newDialer(opts)
+	return err
+}
+
+func (c *PortScanChecker) Check(ctx context.Context, hosts []string, port string) []HostCheckResult {
+	return c.BaseChecker.Check(ctx, hosts, port, c.checkPorts)
+}
+
+func (c *PortScanChecker) checkPorts(ctx context.Context, host string, port string) (map[string]interface{}, error) {
+	open := c.scan(ctx, host)
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("port scan did not finish: %w", err)
+	}
+
+	metadata := map[string]interface{}{
+		"open_ports": open,
+		"open_count": len(open),
+	}
+
+	baseline := c.expected
+	if baseline == nil {
+		// A scan without open ports is most likely an outage, so it is
+		// compared but not kept: the next scan is compared with the ports
+		// open before it rather than reporting all of them as unexpected
+		key := stateKey(ctx, host, port)
+		c.mu.Lock()
+		previous, seen := c.previous[key]
+		if len(open) > 0 {
+			c.previous[key] = open
+		}
+		c.mu.Unlock()
+		if !seen {
+			return metadata, nil
+		}
+		baseline = previous
+	}
+
+	unexpected := portDifference(open, baseline)
+	missing := portDifference(baseline, open)
+	metadata["unexpected_ports"] = unexpected
+	metadata["missing_ports"] = missing
+
+	var problems []string
+	if len(unexpected) > 0 {
+		problems = append(problems, "unexpected open ports "+formatPorts(unexpected))
+	}
+	if len(missing) > 0 {
+		problems = append(problems, "missing open ports "+formatPorts(missing))
+	}
+	if len(problems) > 0 {
+		return metadata, NewCheckError(ErrorAssertion, errors.New(strings.Join(problems, ", ")))
+	}
+	return metadata, nil
+}
+
+// scan returns the sorted list of ports accepting TCP connections.
+func (c *PortScanChecker) scan(ctx context.Context, host string) []int {
+	var (
+		mu   sync.Mutex
+		open = []int{}
+		wg   sync.WaitGroup
+	)
+	sem := make(chan struct{}, c.concurrency)
+
+	for _, port := range c.ports {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+
+		wg.Add(1)
+		go func(port int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			dialCtx, cancel := context.WithTimeout(ctx, c.connectTimeout)
+			defer cancel()
+
+			conn, err := c.dialer.DialContext(dialCtx, "tcp", net.JoinHostPort(host, strconv.Itoa(port)))
+			if err != nil {
+				return
+			}
+			conn.Close()
+
+			mu.Lock()
+			open = append(open, port)
+			mu.Unlock()
+		}(port)
+	}
+
+	wg.Wait()
+	slices.Sort(open)
+	return open
+}
+
+// parsePortList parses entries such as "22", "8000-8100" or "80,443".
+func parsePortList(entries []string) ([]int, error) {
+	seen := make(map[int]bool)
+	var ports []int
+	for _, entry := range entries {
+		for _, part := range strings.Split(entry, ",") {
+			part = strings.TrimSpace(part)
+			if part == "" {
+				continue
+			}
+			lowStr, highStr, isRange := strings.Cut(part, "-")
+			low, err := strconv.Atoi(strings.TrimSpace(lowStr))
+			if err != nil {
+				return nil, fmt.Errorf("invalid port %q", part)
+			}
+			high := low
+			if isRange {
+				if high, err = strconv.Atoi(strings.TrimSpace(highStr)); err != nil {
+					return nil, fmt.Errorf("invalid port range %q", part)
+				}
+			}
+			if low < 1 || high > 65535 || low > high {
+				return nil, fmt.Errorf("invalid port range %q", part)
+			}
+			for p := low; p <= high; p++ {
+				if !seen[p] {
+					seen[p] = true
+					ports = append(ports, p)
+				}
+			}
+		}
+	}
+	slices.Sort(ports)
+	return ports, nil
+}
+
+// portDifference returns the ports in a that are not in b.
+func portDifference(a, b []int) []int {
+	diff := []int{}
+	for _, p := range a {
+		if !slices.Contains(b, p) {
+			diff = append(diff, p)
+		}
+	}
+	return diff
+}
+
+func formatPorts(ports []int) string {
+	parts := make([]string, len(ports))
+	for i, p := range ports {
+		parts[i] = strconv.Itoa(p)
+	}
+	return strings.Join(parts, ",")
+}
+
+func init() {
+	RegisterChecker("PORTSCAN", func() Checker { return NewPortScanChecker() })
+}
@@ -0,0 +1,100 @@
+// Copyright (C) 2025 Jeff Rose
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package checkers
+
+import (
+	"context"
+	"net"
+	"slices"
+	"strconv"
+	"testing"
+)
+
+func TestParsePortList(t *testing.T) {
+	tests := []struct {
+		entries []string
+		want    []int
+		wantErr bool
+	}{
+		{entries: nil, want: nil},
+		{entries: []string{"22"}, want: []int{22}},
+		{entries: []string{"443", "80,22"}, want: []int{22, 80, 443}},
+		{entries: []string{"8000-8003"}, want: []int{8000, 8001, 8002, 8003}},
+		{entries: []string{" 25 , 587 - 588 ,"}, want: []int{25, 587, 588}},
+		{entries: []string{"80", "79-81"}, want: []int{79, 80, 81}},
+		{entries: []string{"65535"}, want: []int{65535}},
+		{entries: []string{"0"}, wantErr: true},
+		{entries: []string{"65536"}, wantErr: true},
+		{entries: []string{"100-90"}, wantErr: true},
+		{entries: []string{"ssh"}, wantErr: true},
+		{entries: []string{"80-http"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := parsePortList(tt.entries)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parsePortList(%q) error = %v, want error %v", tt.entries, err, tt.wantErr)
+			continue
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("parsePortList(%q) = %v, want %v", tt.entries, got, tt.want)
+		}
+	}
+}
+
+func TestPortScanDrift(t *testing.T) {
+	var listeners []net.Listener
+	listen := func() int {
+		l, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatalf("listen: %v", err)
+		}
+		listeners = append(listeners, l)
+		return l.Addr().(*net.TCPAddr).Port
+	}
+	defer func() {
+		for _, l := range listeners {
+			l.Close()
+		}
+	}()
+	ssh, web := listen(), listen()
+
+	checker := NewPortScanChecker()
+	if err := checker.Configure(Options{"ports": []string{strconv.Itoa(ssh), strconv.Itoa(web)}}); err != nil {
+		t.Fatalf("Configure: %v", err)
+	}
+	scan := func() error {
+		_, err := checker.checkPorts(context.Background(), "127.0.0.1", "0")
+		return err
+	}
+
+	if err := scan(); err != nil {
+		t.Fatalf("first scan: %v", err)
+	}
+	listeners[1].Close()
+	if err := scan(); err == nil {
+		t.Error("after closing a port: scan = nil, want drift")
+	}
+	if err := scan(); err != nil {
+		t.Errorf("unchanged since the previous scan: %v", err)
+	}
+	listeners[0].Close()
+	if err := scan(); err == nil {
+		t.Error("with every port closed: scan = nil, want drift")
+	}
+	if err := scan(); err == nil {
+		t.Error("still closed: scan = nil, want drift against the scan before the outage")
+	}
+}
@@ -0,0 +1,327 @@
+// Copyright (C) 2025 Jeff Rose
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package checkers
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	promScrapeMinTimeout     = 1 * time.Second
+	promScrapeMaxTimeout     = 20 * time.Second
+	promScrapeDefaultTimeout = 5 * time.Second
+
+	promScrapeMaxBodyBytes = 32 << 20
+)
+
+var (
+	metricAssertionPattern = regexp.MustCompile(`^\s*([a-zA-Z_:][a-zA-Z0-9_:]*)\s*(\{[^}]*\})?\s*(?:(<=|>=|==|!=|<|>)\s*(\S+))?\s*$`)
+	metricLabelPattern     = regexp.MustCompile(`([a-zA-Z_][a-zA-Z0-9_]*)\s*=\s*"((?:[^"\\]|\\.)*)"`)
+)
+
+// MetricAssertion checks samples matching a selector such as
+// queue_depth{queue="jobs"} against a threshold. Without an operator it only
+// requires the series to exist.
+type MetricAssertion struct {
+	Name     string
+	Metric   string
+	Labels   map[string]string
+	Operator string
+	Value    float64
+	source   string
+}
+
+// UnmarshalYAML accepts either a bare expression or {name, assert}, where
+// name sets the rule variable the matched value is exposed as.
+func (a *MetricAssertion) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var expression string
+	if err := unmarshal(&expression); err != nil {
+		var named struct {
+			Name   string `yaml:"name"`
+			Assert string `yaml:"assert"`
+		}
+		if err := unmarshal(&named); err != nil {
+			return err
+		}
+		expression = named.Assert
+		a.Name = named.Name
+	}
+
+	parsed, err := parseMetricAssertion(expression)
+	if err != nil {
+		return err
+	}
+	parsed.Name = a.Name
+	if parsed.Name == "" {
+		parsed.Name = parsed.Metric
+	}
+	*a = parsed
+	return nil
+}
+
+func parseMetricAssertion(expression string) (MetricAssertion, error) {
+	match := metricAssertionPattern.FindStringSubmatch(expression)
+	if match == nil {
+		return MetricAssertion{}, fmt.Errorf("invalid metric assertion %q", expression)
+	}
+
+	assertion := MetricAssertion{
+		Metric:   match[1],
+		Labels:   parseMetricLabels(match[2]),
+		Operator: match[3],
+		source:   strings.TrimSpace(expression),
+	}
+	if assertion.Operator != "" {
+		value, err := strconv.ParseFloat(match[4], 64)
+		if err != nil {
+			return MetricAssertion{}, fmt.Errorf("invalid threshold in %q", expression)
+		}
+		assertion.Value = value
+	}
+	return assertion, nil
+}
+
+func parseMetricLabels(s string) map[string]string {
+	labels := make(map[string]string)
+	for _, m := range metricLabelPattern.FindAllStringSubmatch(s, -1) {
+		labels[m[1]] = strings.NewReplacer(`\"`, `"`, `\\`, `\`, `\n`, "\n").Replace(m[2])
+	}
+	return labels
+}
+
+func (a MetricAssertion) matches(sample metricSample) bool {
+	if sample.name != a.Metric {
+		return false
+	}
+	for k, v := range a.Labels {
+		if sample.labels[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+func (a MetricAssertion) holds(value float64) bool {
+	switch a.Operator {
+	case "<":
+		return value < a.Value
+	case "<=":
+		return value <= a.Value
+	case ">":
+		return value > a.Value
+	case ">=":
+		return value >= a.Value
+	case "==":
+		return value == a.Value
+	case "!=":
+		return value != a.Value
+	}
+	return true
+}
+
+// worse reports whether value is closer to failing the assertion than
+// current: the lowest value for > and >=, the one furthest from the
+// threshold for ==, and otherwise the highest. A failing value is always
+// worse than a passing one.
+func (a MetricAssertion) worse(value, current float64) bool {
+	if a.holds(value) != a.holds(current) {
+		return !a.holds(value)
+	}
+	switch a.Operator {
+	case ">", ">=":
+		return value < current
+	case "==":
+		return math.Abs(value-a.Value) > math.Abs(current-a.Value)
+	}
+	return value > current
+}
+
+type metricSample struct {
+	name   string
+	labels map[string]string
+	value  float64
+}
+
+// PromScrapeChecker fetches a Prometheus text exposition from each host and
+// evaluates assertions against the samples.
+type PromScrapeChecker struct {
+	BaseChecker
+	scheme     string
+	path       string
+	assertions []MetricAssertion
+	client     *http.Client
+}
+
+func NewPromScrapeChecker() *PromScrapeChecker {
+	return &PromScrapeChecker{
+		BaseChecker: NewBaseChecker(TimeoutBounds{
+			Min:     promScrapeMinTimeout,
+			Max:     promScrapeMaxTimeout,
+			Default: promScrapeDefaultTimeout,
+		}),
+		scheme: "http",
+		path:   "/metrics",
+		client: &http.Client{
+			Transport: newHTTPTransport(&net.Dialer{}),
+		},
+	}
+}
+
+func (c *PromScrapeChecker) Protocol() Protocol {
+	return "PROMSCRAPE"
+}
+
+func (c *PromScrapeChecker) Configure(opts Options) error {
+	c.scheme = strings.ToLower(opts.String("scheme", "http"))
+	if c.scheme != "http" && c.scheme != "https" {
+		return fmt.Errorf("unsupported scheme %q", c.scheme)
+	}
+	c.path = opts.String("path", "/metrics")
+
+	dialer, err := newDialer(opts)
+	if err != nil {
+		return err
+	}
+	transport := newHTTPTransport(dialer)
+	transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: opts.Bool("tls_skip_verify", false)}
+	c.client.Transport = transport
+
+	if err := opts.Decode("assertions", &c.assertions); err != nil {
+		return err
+	}
+	if len(c.assertions) == 0 {
+		return errors.New("at least one assertion must be specified")
+	}
+	return nil
+}
+
+func (c *PromScrapeChecker) Check(ctx context.Context, hosts []string, port string) []HostCheckResult {
+	return c.BaseChecker.Check(ctx, hosts, port, c.checkScrape)
+}
+
+func (c *PromScrapeChecker) checkScrape(ctx context.Context, host string, port string) (map[string]interface{}, error) {
+	url := fmt.Sprintf("%s://%s%s", c.scheme, net.JoinHostPort(host, port), c.path)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "text/plain;version=0.0.4")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("scrape request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		return nil, NewCheckError(ErrorHTTPStatus, fmt.Errorf("scrape status error: %d", resp.StatusCode))
+	}
+
+	samples, err := parseMetricSamples(io.LimitReader(resp.Body, promScrapeMaxBodyBytes))
+	if err != nil {
+		return nil, err
+	}
+
+	metadata := map[string]interface{}{
+		"samples_scraped": len(samples),
+	}
+	var problems []string
+	for _, assertion := range c.assertions {
+		matched := 0
+		worst := math.NaN()
+		for _, sample := range samples {
+			if !assertion.matches(sample) {
+				continue
+			}
+			matched++
+			if math.IsNaN(worst) || assertion.worse(sample.value, worst) {
+				worst = sample.value
+			}
+			if !assertion.holds(sample.value) {
+				problems = append(problems, fmt.Sprintf("%s failed with value %g", assertion.source, sample.value))
+			}
+		}
+
+		if matched == 0 {
+			problems = append(problems, fmt.Sprintf("no samples match %s", assertion.source))
+			continue
+		}
+		metadata[perfDataKey(assertion.Name)] = worst
+	}
+
+	if len(problems) > 0 {
+		return metadata, NewCheckError(ErrorAssertion, errors.New(strings.Join(problems, ", ")))
+	}
+	return metadata, nil
+}
+
+// parseMetricSamples reads the Prometheus text exposition format. Histogram
+// and summary series are returned as their flat _bucket/_sum/_count samples.
+func parseMetricSamples(r io.Reader) ([]metricSample, error) {
+	var samples []metricSample
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1<<20)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		var name, labels, rest string
+		if i := strings.IndexByte(line, '{'); i >= 0 {
+			j := strings.LastIndexByte(line, '}')
+			if j < i {
+				return nil, fmt.Errorf("invalid metric line %q", line)
+			}
+			name, labels, rest = line[:i], line[i:j+1], line[j+1:]
+		} else {
+			name, rest, _ = strings.Cut(line, " ")
+		}
+
+		fields := strings.Fields(rest)
+		if len(fields) == 0 {
+			return nil, fmt.Errorf("invalid metric line %q", line)
+		}
+		value, err := strconv.ParseFloat(fields[0], 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value in metric line %q", line)
+		}
+
+		samples = append(samples, metricSample{
+			name:   strings.TrimSpace(name),
+			labels: parseMetricLabels(labels),
+			value:  value,
+		})
+	}
+	return samples, scanner.Err()
+}
+
+func init() {
+	RegisterChecker("PROMSCRAPE", func() Checker { return NewPromScrapeChecker() })
+}
@@ -0,0 +1,113 @@
+// Copyright (C) 2025 Jeff Rose
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package checkers
+
+import (
+	"maps"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestMetricAssertionWorst(t *testing.T) {
+	tests := []struct {
+		expression string
+		values     []float64
+		want       float64
+	}{
+		{"queue_depth < 100", []float64{3, 40, 7}, 40},
+		{"up >= 1", []float64{1, 0, 1}, 0},
+		{"free_bytes > 1000", []float64{5000, 2000, 9000}, 2000},
+		{"replicas == 3", []float64{3, 2, 5}, 5},
+		{"errors != 0", []float64{4, 0, 9}, 0},
+		{"build_info", []float64{1, 2}, 2},
+	}
+	for _, tt := range tests {
+		assertion, err := parseMetricAssertion(tt.expression)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.expression, err)
+		}
+		worst := math.NaN()
+		for _, value := range tt.values {
+			if math.IsNaN(worst) || assertion.worse(value, worst) {
+				worst = value
+			}
+		}
+		if worst != tt.want {
+			t.Errorf("%s over %v: worst = %g, want %g", tt.expression, tt.values, worst, tt.want)
+		}
+	}
+}
+
+func TestParseMetricSamples(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    []metricSample
+		wantErr bool
+	}{
+		{
+			name:  "comments and blank lines",
+			input: "# HELP up Target up\n# TYPE up gauge\n\nup 1\n",
+			want:  []metricSample{{name: "up", labels: map[string]string{}, value: 1}},
+		},
+		{
+			name:  "labels and timestamp",
+			input: `http_requests_total{code="200",method="get"} 1027 1395066363000`,
+			want:  []metricSample{{name: "http_requests_total", labels: map[string]string{"code": "200", "method": "get"}, value: 1027}},
+		},
+		{
+			name:  "escaped label values",
+			input: `msdos_file_access_time_seconds{path="C:\\DIR\\FILE.TXT",error="Cannot find file:\n\"FILE.TXT\""} 1.458255915e9`,
+			want: []metricSample{{name: "msdos_file_access_time_seconds", labels: map[string]string{
+				"path":  `C:\DIR\FILE.TXT`,
+				"error": "Cannot find file:\n\"FILE.TXT\"",
+			}, value: 1.458255915e9}},
+		},
+		{
+			name:  "histogram bucket",
+			input: `rpc_duration_seconds_bucket{le="+Inf"} 144320`,
+			want:  []metricSample{{name: "rpc_duration_seconds_bucket", labels: map[string]string{"le": "+Inf"}, value: 144320}},
+		},
+		{
+			name:  "special values",
+			input: "a +Inf\nb -Inf\n",
+			want: []metricSample{
+				{name: "a", labels: map[string]string{}, value: math.Inf(1)},
+				{name: "b", labels: map[string]string{}, value: math.Inf(-1)},
+			},
+		},
+		{name: "missing value", input: "up", wantErr: true},
+		{name: "invalid value", input: "up yes", wantErr: true},
+		{name: "unterminated labels", input: `up{job="api" 1`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseMetricSamples(strings.NewReader(tt.input))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseMetricSamples() error = %v, want error %v", err, tt.wantErr)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("parseMetricSamples() = %+v, want %+v", got, tt.want)
+			}
+			for i := range got {
+				if got[i].name != tt.want[i].name || got[i].value != tt.want[i].value || !maps.Equal(got[i].labels, tt.want[i].labels) {
+					t.Errorf("sample %d = %+v, want %+v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
@@ -36,6 +36,20 @@ func NewChecker(protocol Protocol) (Checker, error) {
 	return factory(), nil
 }
 
+// TimeoutBoundsFor returns the timeout bounds of the checker registered for
+// protocol.
+func TimeoutBoundsFor(protocol Protocol) (TimeoutBounds, bool) {
+	checker, err := NewChecker(protocol)
+	if err != nil {
+		return TimeoutBounds{}, false
+	}
+	bounded, ok := checker.(interface{ GetTimeoutBounds() TimeoutBounds })
+	if !ok {
+		return TimeoutBounds{}, false
+	}
+	return bounded.GetTimeoutBounds(), true
+}
+
 // for future use to list all protocols when some cli flag is used
 func ListProtocols() []Protocol {
 	defaultRegistry.mu.RLock()
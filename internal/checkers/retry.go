@@ -0,0 +1,105 @@
+// Copyright (C) 2025 Jeff Rose
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package checkers
+
+import (
+	"context"
+	"time"
+)
+
+// RetryChecker re-runs the wrapped checker for failed hosts within the same
+// round, so a single lost packet does not mark a host down. The number of
+// attempts made is reported as the attempts metadata.
+//
+// Assertion failures are not retried: the service answered, and checkers
+// such as content and port scan watches report a change only once per
+// observation, so a retry would hide it.
+type RetryChecker struct {
+	inner   Checker
+	retries int
+	delay   time.Duration
+}
+
+// NewRetryChecker retries each failed host up to retries times, waiting
+// delay before each retry.
+func NewRetryChecker(inner Checker, retries int, delay time.Duration) *RetryChecker {
+	return &RetryChecker{inner: inner, retries: retries, delay: delay}
+}
+
+func (c *RetryChecker) Protocol() Protocol {
+	return c.inner.Protocol()
+}
+
+func (c *RetryChecker) Check(ctx context.Context, hosts []string, port string) []HostCheckResult {
+	results := c.inner.Check(ctx, hosts, port)
+	attempts := make([]int, len(results))
+	for i := range attempts {
+		attempts[i] = 1
+	}
+
+	for retry := 0; retry < c.retries; retry++ {
+		var failed []int
+		for i, result := range results {
+			if !result.Success && ErrorClassOf(result.Error) != ErrorAssertion {
+				failed = append(failed, i)
+			}
+		}
+		if len(failed) == 0 || !c.wait(ctx) {
+			break
+		}
+
+		retryHosts := make([]string, len(failed))
+		for i, index := range failed {
+			retryHosts[i] = results[index].Host
+		}
+		for i, result := range c.inner.Check(ctx, retryHosts, port) {
+			index := failed[i]
+			results[index] = result
+			attempts[index]++
+		}
+	}
+
+	for i := range results {
+		if results[i].Metadata == nil {
+			results[i].Metadata = make(map[string]interface{})
+		}
+		results[i].Metadata["attempts"] = attempts[i]
+	}
+	return results
+}
+
+// wait sleeps for the retry delay, reporting false if ctx ends first.
+func (c *RetryChecker) wait(ctx context.Context) bool {
+	if c.delay <= 0 {
+		return ctx.Err() == nil
+	}
+	timer := time.NewTimer(c.delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+func (c *RetryChecker) GetTimeout() time.Duration {
+	return c.inner.GetTimeout()
+}
+
+func (c *RetryChecker) SetTimeout(timeout time.Duration) error {
+	return c.inner.SetTimeout(timeout)
+}
@@ -0,0 +1,318 @@
+// Copyright (C) 2025 Jeff Rose
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package checkers
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/textproto"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	sipMinTimeout     = 1 * time.Second
+	sipMaxTimeout     = 20 * time.Second
+	sipDefaultTimeout = 5 * time.Second
+
+	// RFC 3261 timer T1 and T2: UDP requests are retransmitted starting at
+	// T1, doubling up to T2, until a response arrives.
+	sipT1 = 500 * time.Millisecond
+	sipT2 = 4 * time.Second
+
+	sipMaxMessageBytes = 64 << 10
+)
+
+// SIPChecker sends a SIP OPTIONS request to each host and checks the final
+// response code.
+type SIPChecker struct {
+	BaseChecker
+	transport     string
+	expectedCodes []int
+	verifyCert    bool
+	dialer        Dialer
+}
+
+func NewSIPChecker() *SIPChecker {
+	return &SIPChecker{
+		BaseChecker: NewBaseChecker(TimeoutBounds{
+			Min:     sipMinTimeout,
+			Max:     sipMaxTimeout,
+			Default: sipDefaultTimeout,
+		}),
+		transport:     "udp",
+		expectedCodes: []int{200},
+		dialer:        &net.Dialer{},
+	}
+}
+
+func (c *SIPChecker) Protocol() Protocol {
+	return "SIP"
+}
+
+func (c *SIPChecker) Configure(opts Options) error {
+	c.transport = strings.ToLower(opts.String("transport", "udp"))
+	switch c.transport {
+	case "udp", "tcp", "tls":
+	default:
+		return fmt.Errorf("unsupported transport %q, expected udp, tcp or tls", c.transport)
+	}
+	c.verifyCert = opts.Bool("verify_cert", false)
+
+	dialer, err := newDialer(opts)
+	if err != nil {
+		return err
+	}
+	if c.transport == "udp" && isProxied(dialer) {
+		return errors.New("the udp transport cannot be used through a proxy")
+	}
+	c.dialer = dialer
+
+	if codes := opts.StringSlice("expected_codes"); len(codes) > 0 {
+		c.expectedCodes = c.expectedCodes[:0]
+		for _, code := range codes {
+			n, err := strconv.Atoi(code)
+			if err != nil || n < 100 || n > 699 {
+				return fmt.Errorf("invalid response code %q", code)
+			}
+			c.expectedCodes = append(c.expectedCodes, n)
+		}
+	}
+	return nil
+}
+
+func (c *SIPChecker) Check(ctx context.Context, hosts []string, port string) []HostCheckResult {
+	return c.BaseChecker.Check(ctx, hosts, port, c.checkSIP)
+}
+
+type sipResponse struct {
+	code   int
+	reason string
+	header textproto.MIMEHeader
+}
+
+func (c *SIPChecker) checkSIP(ctx context.Context, host string, port string) (map[string]interface{}, error) {
+	conn, err := c.dial(ctx, host, port)
+	if err != nil {
+		return nil, fmt.Errorf("sip connection failed: %w", err)
+	}
+	defer conn.Close()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	callID := randomHex(16) + "@checkmate"
+	request := c.buildOptions(host, port, conn.LocalAddr().String(), callID)
+
+	start := time.Now()
+	var resp *sipResponse
+	if c.transport == "udp" {
+		resp, err = exchangeSIPDatagram(ctx, conn, request, callID)
+	} else {
+		resp, err = exchangeSIPStream(conn, request, callID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	metadata := map[string]interface{}{
+		"response_code": resp.code,
+		"reason":        resp.reason,
+		"sip_latency":   time.Since(start),
+	}
+	if server := resp.header.Get("Server"); server != "" {
+		metadata["server"] = server
+	} else if agent := resp.header.Get("User-Agent"); agent != "" {
+		metadata["server"] = agent
+	}
+
+	if !slices.Contains(c.expectedCodes, resp.code) {
+		return metadata, NewCheckError(ErrorHTTPStatus, fmt.Errorf("sip status error: %d %s", resp.code, resp.reason))
+	}
+	return metadata, nil
+}
+
+func (c *SIPChecker) dial(ctx context.Context, host, port string) (net.Conn, error) {
+	address := net.JoinHostPort(host, port)
+	switch c.transport {
+	case "udp":
+		return c.dialer.DialContext(ctx, "udp", address)
+	case "tls":
+		return dialTLS(ctx, c.dialer, "tcp", address, &tls.Config{
+			ServerName:         host,
+			InsecureSkipVerify: !c.verifyCert,
+		})
+	default:
+		return c.dialer.DialContext(ctx, "tcp", address)
+	}
+}
+
+func (c *SIPChecker) buildOptions(host, port, localAddr, callID string) []byte {
+	scheme := "sip"
+	if c.transport == "tls" {
+		scheme = "sips"
+	}
+	target := fmt.Sprintf("%s:%s", scheme, net.JoinHostPort(host, port))
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "OPTIONS %s SIP/2.0\r\n", target)
+	fmt.Fprintf(&b, "Via: SIP/2.0/%s %s;branch=z9hG4bK%s;rport\r\n", strings.ToUpper(c.transport), localAddr, randomHex(8))
+	fmt.Fprintf(&b, "Max-Forwards: 70\r\n")
+	fmt.Fprintf(&b, "From: <%s:checkmate@%s>;tag=%s\r\n", scheme, localAddr, randomHex(8))
+	fmt.Fprintf(&b, "To: <%s>\r\n", target)
+	fmt.Fprintf(&b, "Call-ID: %s\r\n", callID)
+	fmt.Fprintf(&b, "CSeq: 1 OPTIONS\r\n")
+	fmt.Fprintf(&b, "Contact: <%s:checkmate@%s>\r\n", scheme, localAddr)
+	fmt.Fprintf(&b, "Accept: application/sdp\r\n")
+	fmt.Fprintf(&b, "User-Agent: CheckMate\r\n")
+	fmt.Fprintf(&b, "Content-Length: 0\r\n\r\n")
+	return b.Bytes()
+}
+
+// exchangeSIPDatagram sends the request over UDP, retransmitting until a
+// final response for the call arrives or the context expires.
+func exchangeSIPDatagram(ctx context.Context, conn net.Conn, request []byte, callID string) (*sipResponse, error) {
+	buf := make([]byte, sipMaxMessageBytes)
+	interval := sipT1
+	provisional := false
+
+	for {
+		if !provisional {
+			if _, err := conn.Write(request); err != nil {
+				return nil, fmt.Errorf("sip send failed: %w", err)
+			}
+		}
+
+		wait := time.Now().Add(interval)
+		if deadline, ok := ctx.Deadline(); ok && deadline.Before(wait) {
+			wait = deadline
+		}
+		_ = conn.SetReadDeadline(wait)
+
+		n, err := conn.Read(buf)
+		if err != nil {
+			var netErr net.Error
+			if errors.As(err, &netErr) && netErr.Timeout() && ctx.Err() == nil {
+				interval = min(interval*2, sipT2)
+				continue
+			}
+			return nil, fmt.Errorf("sip receive failed: %w", err)
+		}
+
+		resp, err := parseSIPResponse(bufio.NewReader(bytes.NewReader(buf[:n])))
+		if err != nil {
+			return nil, err
+		}
+		if resp.header.Get("Call-Id") != callID {
+			continue
+		}
+		if resp.code < 200 {
+			// A provisional response stops retransmissions (RFC 3261 17.1.2.2)
+			provisional = true
+			interval = sipT2
+			continue
+		}
+		return resp, nil
+	}
+}
+
+func exchangeSIPStream(conn net.Conn, request []byte, callID string) (*sipResponse, error) {
+	if _, err := conn.Write(request); err != nil {
+		return nil, fmt.Errorf("sip send failed: %w", err)
+	}
+
+	reader := bufio.NewReader(io.LimitReader(conn, sipMaxMessageBytes*4))
+	for {
+		resp, err := parseSIPResponse(reader)
+		if err != nil {
+			return nil, err
+		}
+		// Skip the body so the next message starts at a message boundary
+		if length, _ := strconv.Atoi(resp.header.Get("Content-Length")); length > 0 {
+			if _, err := io.CopyN(io.Discard, reader, int64(length)); err != nil {
+				return nil, fmt.Errorf("sip receive failed: %w", err)
+			}
+		}
+		if resp.header.Get("Call-Id") == callID && resp.code >= 200 {
+			return resp, nil
+		}
+	}
+}
+
+func parseSIPResponse(r *bufio.Reader) (*sipResponse, error) {
+	tp := textproto.NewReader(r)
+	line, err := tp.ReadLine()
+	if err != nil {
+		return nil, fmt.Errorf("sip receive failed: %w", err)
+	}
+
+	version, status, ok := strings.Cut(line, " ")
+	if !ok || version != "SIP/2.0" {
+		return nil, fmt.Errorf("invalid sip status line %q", line)
+	}
+	codeStr, reason, _ := strings.Cut(status, " ")
+	code, err := strconv.Atoi(codeStr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sip status line %q", line)
+	}
+
+	header, err := tp.ReadMIMEHeader()
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("invalid sip headers: %w", err)
+	}
+	expandSIPCompactHeaders(header)
+
+	return &sipResponse{
+		code:   code,
+		reason: reason,
+		header: header,
+	}, nil
+}
+
+// expandSIPCompactHeaders maps the compact header forms used by some
+// servers to their full names.
+func expandSIPCompactHeaders(header textproto.MIMEHeader) {
+	compact := map[string]string{
+		"I": "Call-Id",
+		"L": "Content-Length",
+	}
+	for short, full := range compact {
+		if values, ok := header[short]; ok && header.Get(full) == "" {
+			header[full] = values
+		}
+	}
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+func init() {
+	RegisterChecker("SIP", func() Checker { return NewSIPChecker() })
+}
@@ -18,6 +18,7 @@ package checkers
 import (
 	"context"
 	"fmt"
+	"net"
 	"net/smtp"
 	"sync"
 	"time"
@@ -31,7 +32,8 @@ const (
 
 type SMTPChecker struct {
 	BaseChecker
-	mu sync.RWMutex
+	mu     sync.RWMutex
+	dialer Dialer
 }
 
 func NewSMTPChecker() *SMTPChecker {
@@ -41,6 +43,7 @@ func NewSMTPChecker() *SMTPChecker {
 			Max:     smtpMaxTimeout,
 			Default: smtpDefaultTimeout,
 		}),
+		dialer: &net.Dialer{},
 	}
 }
 
@@ -48,14 +51,37 @@ func (c *SMTPChecker) Protocol() Protocol {
 	return "SMTP"
 }
 
+func (c *SMTPChecker) Configure(opts Options) error {
+	dialer, err := newDialer(opts)
+	if err != nil {
+		return err
+	}
+	c.mu.Lock()
+	c.dialer = dialer
+	c.mu.Unlock()
+	return nil
+}
+
 func (c *SMTPChecker) Check(ctx context.Context, hosts []string, port string) []HostCheckResult {
 	return c.BaseChecker.Check(ctx, hosts, port, c.checkSMTP)
 }
 
 func (c *SMTPChecker) checkSMTP(ctx context.Context, host string, port string) (map[string]interface{}, error) {
-	address := fmt.Sprintf("%s:%s", host, port)
-	client, err := smtp.Dial(address)
+	c.mu.RLock()
+	dialer := c.dialer
+	c.mu.RUnlock()
+
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(host, port))
+	if err != nil {
+		return nil, fmt.Errorf("smtp connection failed: %w", err)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	client, err := smtp.NewClient(conn, host)
 	if err != nil {
+		conn.Close()
 		return nil, fmt.Errorf("smtp connection failed: %w", err)
 	}
 	defer client.Close()
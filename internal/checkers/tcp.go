@@ -31,7 +31,9 @@ const (
 
 type TCPChecker struct {
 	BaseChecker
-	mu sync.RWMutex
+	mu           sync.RWMutex
+	expectClosed bool
+	dialer       Dialer
 }
 
 func NewTCPChecker() *TCPChecker {
@@ -41,6 +43,7 @@ func NewTCPChecker() *TCPChecker {
 			Max:     tcpMaxTimeout,
 			Default: tcpDefaultTimeout,
 		}),
+		dialer: &net.Dialer{},
 	}
 }
 
@@ -48,17 +51,41 @@ func (c *TCPChecker) Protocol() Protocol {
 	return "TCP"
 }
 
+func (c *TCPChecker) Configure(opts Options) error {
+	closed, err := expectClosed(opts)
+	if err != nil {
+		return err
+	}
+	dialer, err := newDialer(opts)
+	if err != nil {
+		return err
+	}
+	c.mu.Lock()
+	c.expectClosed = closed
+	c.dialer = dialer
+	c.mu.Unlock()
+	return nil
+}
+
 func (c *TCPChecker) Check(ctx context.Context, hosts []string, port string) []HostCheckResult {
 	return c.BaseChecker.Check(ctx, hosts, port, c.checkTCP)
 }
 
 func (c *TCPChecker) checkTCP(ctx context.Context, host string, port string) (map[string]interface{}, error) {
-	address := fmt.Sprintf("%s:%s", host, port)
-	conn, err := net.DialTimeout("tcp", address, c.GetTimeout())
+	c.mu.RLock()
+	closed, dialer := c.expectClosed, c.dialer
+	c.mu.RUnlock()
+
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(host, port))
+	if conn != nil {
+		defer conn.Close()
+	}
+	if closed {
+		return verifyClosed(err)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("tcp connection failed: %w", err)
 	}
-	defer conn.Close()
 	return nil, nil
 }
 
@@ -0,0 +1,182 @@
+// Copyright (C) 2025 Jeff Rose
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/whiskeyjimbo/CheckMate/internal/checkers"
+)
+
+var loadPlugin sync.Once
+
+// loadTestPlugin registers a REDIS plugin that describes itself and then
+// ignores every request.
+func loadTestPlugin(t *testing.T) {
+	loadPlugin.Do(func() {
+		dir := t.TempDir()
+		script := "#!/bin/sh\nread line\necho '{\"type\":\"describe\",\"protocol\":\"REDIS\"}'\ncat >/dev/null\n"
+		if err := os.WriteFile(filepath.Join(dir, "redis"), []byte(script), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := checkers.LoadPlugins(context.Background(), dir); err != nil {
+			t.Fatalf("LoadPlugins: %v", err)
+		}
+	})
+}
+
+func TestCheckConfigPortFor(t *testing.T) {
+	tests := []struct {
+		name  string
+		check CheckConfig
+		host  HostConfig
+		want  string
+	}{
+		{"check port", CheckConfig{Port: "22"}, HostConfig{Host: "a"}, "22"},
+		{"host port needs opt in", CheckConfig{Port: "22"}, HostConfig{Host: "a", Port: "8081"}, "22"},
+		{"host port", CheckConfig{Port: "8080", UseHostPorts: true}, HostConfig{Host: "a", Port: "8081"}, "8081"},
+		{"host without port", CheckConfig{Port: "8080", UseHostPorts: true}, HostConfig{Host: "a"}, "8080"},
+		{"host_ports wins", CheckConfig{Port: "8080", UseHostPorts: true, HostPorts: map[string]string{"a": "9101"}}, HostConfig{Host: "a", Port: "8081"}, "9101"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.check.PortFor(tt.host); got != tt.want {
+				t.Errorf("PortFor() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeHostCheckPort(t *testing.T) {
+	c := &Config{Sites: []SiteConfig{{Groups: []GroupConfig{{
+		Hosts: []HostConfig{{
+			Host:   "10.0.0.5:8081",
+			Checks: []CheckConfig{{Protocol: "tcp"}, {Port: "22", Protocol: "tcp"}},
+		}},
+	}}}}}
+	normalizeConfig(c)
+
+	host := c.Sites[0].Groups[0].Hosts[0]
+	if host.Host != "10.0.0.5" || host.Port != "8081" {
+		t.Fatalf("host = %q port %q, want 10.0.0.5 port 8081", host.Host, host.Port)
+	}
+	if got := host.Checks[0].PortFor(host); got != "8081" {
+		t.Errorf("check without port: PortFor() = %q, want 8081", got)
+	}
+	if got := host.Checks[1].PortFor(host); got != "22" {
+		t.Errorf("check with port: PortFor() = %q, want 22", got)
+	}
+}
+
+func TestHostConfigValidate(t *testing.T) {
+	tests := []struct {
+		host    HostConfig
+		wantErr bool
+	}{
+		{HostConfig{Host: "api-1"}, false},
+		{HostConfig{Host: "10.0.0.5:8081"}, false},
+		{HostConfig{Host: "[2001:db8::1]:8081"}, false},
+		{HostConfig{Host: "2001:db8::1"}, false},
+		{HostConfig{Host: "api-1", Port: "8081"}, false},
+		{HostConfig{Host: "10.0.0.5:8081", Port: "8082"}, true},
+		{HostConfig{Host: "10.0.0.5:99999"}, true},
+		{HostConfig{Host: "api-1", Port: "http"}, true},
+	}
+	for _, tt := range tests {
+		if err := tt.host.Validate(); (err != nil) != tt.wantErr {
+			t.Errorf("%+v: Validate() error = %v, want error %v", tt.host, err, tt.wantErr)
+		}
+	}
+}
+
+func TestPluginCheckSettings(t *testing.T) {
+	loadTestPlugin(t)
+	c := &Config{Sites: []SiteConfig{{
+		Proxy: "socks5://bastion:1080",
+		Groups: []GroupConfig{{
+			Name:   "db",
+			Source: SourceConfig{SourceIP: "10.0.0.1"},
+			Checks: []CheckConfig{
+				{Port: "6379", Protocol: "redis", Interval: "10s"},
+				{Port: "22", Protocol: "tcp", Interval: "10s"},
+			},
+		}},
+	}}}
+	normalizeConfig(c)
+
+	group := c.Sites[0].Groups[0]
+	if plugin := group.Checks[0]; plugin.Proxy != "" || plugin.Source != (SourceConfig{}) {
+		t.Errorf("plugin check inherited proxy %q and source %+v", plugin.Proxy, plugin.Source)
+	}
+	if builtin := group.Checks[1]; builtin.Proxy != "socks5://bastion:1080" || builtin.Source.SourceIP != "10.0.0.1" {
+		t.Errorf("built-in check did not inherit proxy %q and source %+v", builtin.Proxy, builtin.Source)
+	}
+	if err := group.Validate(); err != nil {
+		t.Errorf("Validate() = %v, want nil", err)
+	}
+
+	tests := []CheckConfig{
+		{Proxy: "http://squid:3128"},
+		{Source: SourceConfig{Interface: "eth1"}},
+		{VerifyCert: true},
+		{AddressFamily: FamilyIPv6},
+		{Expect: ExpectClosed},
+	}
+	for _, check := range tests {
+		check.Port, check.Protocol = "6379", "REDIS"
+		if err := check.Validate(); err == nil {
+			t.Errorf("%+v: Validate() = nil, want error", check)
+		}
+	}
+}
+
+func TestAddressFamilyForLocalProtocols(t *testing.T) {
+	tests := []struct {
+		check   CheckConfig
+		wantErr bool
+	}{
+		{CheckConfig{Protocol: "TCP", AddressFamily: FamilyBoth}, false},
+		{CheckConfig{Protocol: "HTTPS", AddressFamily: FamilyIPv6, ResolveAll: true}, false},
+		{CheckConfig{Protocol: "HEARTBEAT"}, false},
+		{CheckConfig{Protocol: "HEARTBEAT", AddressFamily: FamilyBoth}, true},
+		{CheckConfig{Protocol: "DISK", AddressFamily: FamilyIPv4}, true},
+		{CheckConfig{Protocol: "docker", AddressFamily: FamilyIPv6}, true},
+		{CheckConfig{Protocol: "HEARTBEAT", ResolveAll: true}, true},
+		{CheckConfig{Protocol: "MEMORY", ResolveAll: true}, true},
+	}
+	for _, tt := range tests {
+		tt.check.Port = "0"
+		if err := tt.check.Validate(); (err != nil) != tt.wantErr {
+			t.Errorf("%s with address_family %q, resolve_all %v: Validate() error = %v, want error %v",
+				tt.check.Protocol, tt.check.AddressFamily, tt.check.ResolveAll, err, tt.wantErr)
+		}
+	}
+}
+
+func TestUnknownProtocol(t *testing.T) {
+	loadTestPlugin(t)
+	for _, protocol := range []string{"TPC", "HTTPS2", "MEMCACHED"} {
+		check := CheckConfig{Port: "80", Protocol: protocol, Proxy: "http://squid:3128"}
+		err := check.Validate()
+		if err == nil || err.Error() != "check "+protocol+"/80: unknown protocol \""+protocol+"\"" {
+			t.Errorf("%s: Validate() = %v, want unknown protocol", protocol, err)
+		}
+	}
+}
@@ -0,0 +1,136 @@
+// Copyright (C) 2025 Jeff Rose
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package heartbeat
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+type Signal string
+
+const (
+	SignalSuccess Signal = "success"
+	SignalStart   Signal = "start"
+	SignalFail    Signal = "fail"
+)
+
+// Status is the last known state of a heartbeat token.
+type Status struct {
+	Registered   time.Time
+	LastPing     time.Time
+	LastStart    time.Time
+	LastSignal   Signal
+	LastDuration time.Duration
+}
+
+var (
+	mu       sync.RWMutex
+	statuses = make(map[string]*Status)
+)
+
+// Claim starts tracking the tokens of a check. It fails without registering
+// any of them if one is already tracked, as two checks would otherwise
+// consume the same pings.
+func Claim(tokens ...string) error {
+	mu.Lock()
+	defer mu.Unlock()
+	for _, token := range tokens {
+		if _, exists := statuses[token]; exists {
+			return fmt.Errorf("heartbeat token %q is already used by another check", token)
+		}
+	}
+	now := time.Now()
+	for _, token := range tokens {
+		statuses[token] = &Status{Registered: now}
+	}
+	return nil
+}
+
+func Get(token string) (Status, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+	status, exists := statuses[token]
+	if !exists {
+		return Status{}, false
+	}
+	return *status, true
+}
+
+func record(token string, signal Signal, duration time.Duration) bool {
+	mu.Lock()
+	defer mu.Unlock()
+
+	status, exists := statuses[token]
+	if !exists {
+		return false
+	}
+
+	now := time.Now()
+	status.LastSignal = signal
+	if signal == SignalStart {
+		status.LastStart = now
+		return true
+	}
+
+	if duration == 0 && !status.LastStart.IsZero() && status.LastStart.After(status.LastPing) {
+		duration = now.Sub(status.LastStart)
+	}
+	status.LastPing = now
+	status.LastDuration = duration
+	return true
+}
+
+// PingHandler serves /heartbeat/{token} and /heartbeat/{token}/{signal}. An
+// optional duration query parameter accepts seconds or a Go duration string.
+func PingHandler(w http.ResponseWriter, r *http.Request) {
+	signal := SignalSuccess
+	if s := r.PathValue("signal"); s != "" {
+		signal = Signal(s)
+	}
+	switch signal {
+	case SignalSuccess, SignalStart, SignalFail:
+	default:
+		http.Error(w, "unknown signal", http.StatusBadRequest)
+		return
+	}
+
+	duration, err := parseDuration(r.URL.Query().Get("duration"))
+	if err != nil {
+		http.Error(w, "invalid duration", http.StatusBadRequest)
+		return
+	}
+
+	if !record(r.PathValue("token"), signal, duration) {
+		http.Error(w, "unknown token", http.StatusNotFound)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write([]byte("OK\n"))
+}
+
+func parseDuration(s string) (time.Duration, error) {
+	if s == "" {
+		return 0, nil
+	}
+	if seconds, err := strconv.ParseFloat(s, 64); err == nil {
+		return time.Duration(seconds * float64(time.Second)), nil
+	}
+	return time.ParseDuration(s)
+}
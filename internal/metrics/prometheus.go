@@ -19,6 +19,7 @@ import (
 	"fmt"
 	"net/http"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
@@ -26,6 +27,7 @@ import (
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/whiskeyjimbo/CheckMate/internal/checkers"
 	"github.com/whiskeyjimbo/CheckMate/internal/health"
+	"github.com/whiskeyjimbo/CheckMate/internal/heartbeat"
 	"go.uber.org/zap"
 )
 
@@ -40,6 +42,11 @@ type MetricLabels struct {
 	Host     string
 	Port     string
 	Protocol string
+	Source   string
+	Family   string
+	Address  string
+	// ErrorClass is the class of a failed check, empty on success
+	ErrorClass string
 }
 
 type PrometheusMetrics struct {
@@ -49,6 +56,7 @@ type PrometheusMetrics struct {
 	checkStatus  *prometheus.GaugeVec
 	checkLatency *prometheus.GaugeVec
 	latencyHist  *prometheus.HistogramVec
+	checkErrors  *prometheus.CounterVec
 
 	// Graph metrics
 	nodeInfo   *prometheus.GaugeVec
@@ -56,15 +64,39 @@ type PrometheusMetrics struct {
 	hostsUp    *prometheus.GaugeVec
 	hostsTotal *prometheus.GaugeVec
 
-	// Certificate metrics
-	certExpiryDays *prometheus.GaugeVec
-	monitorSite    string
+	// Expiry metrics
+	certExpiryDays   *prometheus.GaugeVec
+	domainExpiryDays *prometheus.GaugeVec
+
+	// Plugin metrics
+	perfData *prometheus.GaugeVec
+
+	// Checker metadata
+	metadata *prometheus.GaugeVec
+	// metadataKeys holds the keys last exported for each host series, so
+	// keys a host no longer reports can be deleted
+	metadataMu   sync.Mutex
+	metadataKeys map[string]map[string]bool
+
+	monitorSite string
 }
 
 type HostResult struct {
+	// Host, Family and Address identify the result when the map key alone
+	// does not, as with hosts checked once per address family or address.
+	Host         string
+	Family       string
+	Address      string
 	Success      bool
 	ResponseTime time.Duration
 	Error        error
+	Metadata     map[string]interface{}
+	// ErrorClass classifies Error, see checkers.ErrorClassOf
+	ErrorClass checkers.ErrorClass
+	// Tags replaces the group tags for hosts with tags of their own
+	Tags []string
+	// Port replaces the group port for hosts checked on a port of their own
+	Port string
 }
 
 type GroupMetrics struct {
@@ -72,6 +104,7 @@ type GroupMetrics struct {
 	Group       string
 	Port        string
 	Protocol    string
+	Source      string
 	Tags        []string
 	HostResults map[string]HostResult
 	HostsUp     int
@@ -80,8 +113,9 @@ type GroupMetrics struct {
 
 func NewPrometheusMetrics(logger *zap.SugaredLogger, monitorSite string) *PrometheusMetrics {
 	p := &PrometheusMetrics{
-		logger:      logger,
-		monitorSite: monitorSite,
+		logger:       logger,
+		monitorSite:  monitorSite,
+		metadataKeys: make(map[string]map[string]bool),
 	}
 	p.initMetrics()
 	return p
@@ -91,10 +125,14 @@ func (p *PrometheusMetrics) initMetrics() {
 	p.checkStatus = createCheckStatusMetric()
 	p.checkLatency = createCheckLatencyMetric()
 	p.latencyHist = createLatencyHistogram()
+	p.checkErrors = createCheckErrorsMetric()
 	p.hostsUp, p.hostsTotal = createHostCountMetrics()
 	p.nodeInfo = createNodeMetric()
 	p.edgeInfo = createEdgeMetric()
 	p.certExpiryDays = createCertExpiryMetric()
+	p.domainExpiryDays = createDomainExpiryMetric()
+	p.perfData = createPerfDataMetric()
+	p.metadata = createMetadataMetric()
 }
 
 func StartMetricsServer(logger *zap.SugaredLogger) {
@@ -102,6 +140,8 @@ func StartMetricsServer(logger *zap.SugaredLogger) {
 	mux.Handle("/metrics", promhttp.Handler())
 	mux.HandleFunc("/health/live", health.LivenessHandler)
 	mux.HandleFunc("/health/ready", health.ReadinessHandler)
+	mux.HandleFunc("/heartbeat/{token}", heartbeat.PingHandler)
+	mux.HandleFunc("/heartbeat/{token}/{signal}", heartbeat.PingHandler)
 
 	server := &http.Server{
 		Addr:              metricsPort,
@@ -121,14 +161,37 @@ func StartMetricsServer(logger *zap.SugaredLogger) {
 
 func (p *PrometheusMetrics) UpdateGroup(metrics GroupMetrics) {
 	for host, result := range metrics.HostResults {
+		if result.Host != "" {
+			host = result.Host
+		}
+		port := metrics.Port
+		if result.Port != "" {
+			port = result.Port
+		}
 		labels := MetricLabels{
-			Site:     metrics.Site,
-			Group:    metrics.Group,
-			Host:     host,
-			Port:     metrics.Port,
-			Protocol: metrics.Protocol,
+			Site:       metrics.Site,
+			Group:      metrics.Group,
+			Host:       host,
+			Port:       port,
+			Protocol:   metrics.Protocol,
+			Source:     metrics.Source,
+			Family:     result.Family,
+			Address:    result.Address,
+			ErrorClass: string(result.ErrorClass),
+		}
+		hostTags := metrics.Tags
+		if result.Tags != nil {
+			hostTags = result.Tags
+		}
+		p.updateMetrics(labels, hostTags, result.Success, result.ResponseTime)
+		p.updatePerfData(labels, result.Metadata)
+		p.updateMetadata(labels, hostTags, result.Metadata)
+		if certInfo, ok := result.Metadata["cert_info"].(*checkers.CertInfo); ok {
+			p.UpdateCertificate(metrics.Site, metrics.Group, host, port, certInfo)
+		}
+		if domainInfo, ok := result.Metadata["domain_info"].(*checkers.DomainInfo); ok {
+			p.UpdateDomain(metrics.Site, metrics.Group, host, domainInfo)
 		}
-		p.updateMetrics(labels, metrics.Tags, result.Success, result.ResponseTime)
 	}
 	p.updateGroupCounts(metrics.Site, metrics.Group, metrics.Port, metrics.Protocol, metrics.HostsUp, metrics.HostsTotal)
 }
@@ -136,9 +199,9 @@ func (p *PrometheusMetrics) UpdateGroup(metrics GroupMetrics) {
 func (p *PrometheusMetrics) updateMetrics(labels MetricLabels, tags []string, success bool, elapsed time.Duration) {
 	tagString := normalizeTagString(tags)
 
-	fullLabels := []string{labels.Site, labels.Group, labels.Host, labels.Port, labels.Protocol, tagString}
+	fullLabels := []string{labels.Site, labels.Group, labels.Host, labels.Port, labels.Protocol, tagString, labels.Source, labels.Family, labels.Address}
 
-	histLabels := []string{labels.Site, labels.Group, labels.Port, labels.Protocol, tagString}
+	histLabels := []string{labels.Site, labels.Group, labels.Port, labels.Protocol, tagString, labels.Source, labels.Family}
 
 	statusValue := 0.0
 	if success {
@@ -150,6 +213,8 @@ func (p *PrometheusMetrics) updateMetrics(labels MetricLabels, tags []string, su
 	if success {
 		p.checkLatency.WithLabelValues(fullLabels...).Set(float64(elapsed.Milliseconds()))
 		p.latencyHist.WithLabelValues(histLabels...).Observe(float64(elapsed.Seconds()))
+	} else {
+		p.checkErrors.WithLabelValues(append(fullLabels, labels.ErrorClass)...).Inc()
 	}
 
 	p.updateGraphMetrics(labels, tagString, success, elapsed)
@@ -287,6 +352,146 @@ func (p *PrometheusMetrics) UpdateCertificate(site, group, host, port string, ce
 	}).Set(daysUntilExpiry)
 }
 
+func (p *PrometheusMetrics) UpdateDomain(site, group, host string, domainInfo *checkers.DomainInfo) {
+	if domainInfo == nil || domainInfo.ExpiresAt.IsZero() {
+		return
+	}
+
+	daysUntilExpiry := time.Until(domainInfo.ExpiresAt).Hours() / 24
+	p.domainExpiryDays.With(prometheus.Labels{
+		"site":      site,
+		"group":     group,
+		"host":      host,
+		"registrar": domainInfo.Registrar,
+	}).Set(daysUntilExpiry)
+}
+
+func (p *PrometheusMetrics) updatePerfData(labels MetricLabels, metadata map[string]interface{}) {
+	perfData, ok := metadata["perfdata"].([]checkers.PerfData)
+	if !ok {
+		return
+	}
+
+	for _, pd := range perfData {
+		p.perfData.With(prometheus.Labels{
+			"site":     labels.Site,
+			"group":    labels.Group,
+			"host":     labels.Host,
+			"port":     labels.Port,
+			"protocol": labels.Protocol,
+			"label":    pd.Label,
+			"uom":      pd.UOM,
+		}).Set(pd.Value)
+	}
+}
+
+// exportedMetadata lists the metadata keys exported by updateMetadata.
+// Plugins and check options can report any key, which would give the
+// metric unbounded cardinality.
+var exportedMetadata = map[string]bool{
+	"age":                    true,
+	"attempts":               true,
+	"broken_count":           true,
+	"containers_running":     true,
+	"containers_unhealthy":   true,
+	"cpus":                   true,
+	"delivery_time":          true,
+	"disk_used_percent":      true,
+	"domain_days_remaining":  true,
+	"exit_code":              true,
+	"inode_used_percent":     true,
+	"last_duration":          true,
+	"load1":                  true,
+	"load5":                  true,
+	"load15":                 true,
+	"matches":                true,
+	"memory_available_bytes": true,
+	"memory_used_percent":    true,
+	"open_count":             true,
+	"pages_checked":          true,
+	"process_count":          true,
+	"response_code":          true,
+	"restart_count":          true,
+	"samples_scraped":        true,
+	"since_last_ping":        true,
+	"sip_latency":            true,
+	"size_bytes":             true,
+	"swap_used_percent":      true,
+}
+
+// isExportedMetadata reports whether key is exported, including the step
+// timings of HTTPFLOW checks, which are bounded by the configured steps.
+func isExportedMetadata(key string) bool {
+	return exportedMetadata[key] || strings.HasPrefix(key, "step_") && strings.HasSuffix(key, "_time")
+}
+
+// updateMetadata exports the numeric metadata of a host, durations in
+// seconds, so values such as delivery times can be graphed. Keys the host
+// reported last time but not now are deleted, as are series with old tags.
+func (p *PrometheusMetrics) updateMetadata(labels MetricLabels, tags []string, metadata map[string]interface{}) {
+	series := prometheus.Labels{
+		"site":     labels.Site,
+		"group":    labels.Group,
+		"host":     labels.Host,
+		"port":     labels.Port,
+		"protocol": labels.Protocol,
+		"source":   labels.Source,
+		"family":   labels.Family,
+		"address":  labels.Address,
+	}
+	id := strings.Join([]string{labels.Site, labels.Group, labels.Host, labels.Port, labels.Protocol, labels.Source, labels.Family, labels.Address}, "\xff")
+	tagString := normalizeTagString(tags)
+
+	values := make(map[string]float64)
+	for key, value := range metadata {
+		if !isExportedMetadata(key) {
+			continue
+		}
+		switch v := value.(type) {
+		case time.Duration:
+			values[key] = v.Seconds()
+		case int:
+			values[key] = float64(v)
+		case int64:
+			values[key] = float64(v)
+		case uint64:
+			values[key] = float64(v)
+		case float64:
+			values[key] = v
+		}
+	}
+
+	p.metadataMu.Lock()
+	defer p.metadataMu.Unlock()
+	exported := make(map[string]bool, len(values))
+	for key := range values {
+		exported[key+"\xff"+tagString] = true
+	}
+	for entry := range p.metadataKeys[id] {
+		if !exported[entry] {
+			key, _, _ := strings.Cut(entry, "\xff")
+			p.metadata.DeletePartialMatch(withLabels(series, "key", key))
+		}
+	}
+	for key, value := range values {
+		p.metadata.With(withLabels(series, "tags", tagString, "key", key)).Set(value)
+	}
+	p.metadataKeys[id] = exported
+}
+
+// withLabels returns a copy of labels with the given name and value pairs
+// added.
+func withLabels(labels prometheus.Labels, pairs ...string) prometheus.Labels {
+	result := make(prometheus.Labels, len(labels)+len(pairs)/2)
+	for name, value := range labels {
+		result[name] = value
+	}
+	for i := 0; i+1 < len(pairs); i += 2 {
+		result[pairs[i]] = pairs[i+1]
+	}
+	return result
+}
+
 func createCheckStatusMetric() *prometheus.GaugeVec {
 	return promauto.NewGaugeVec(
 		prometheus.GaugeOpts{
@@ -294,7 +499,7 @@ func createCheckStatusMetric() *prometheus.GaugeVec {
 			Name:      "host_check_status",
 			Help:      "Status of the host check (1 for up, 0 for down)",
 		},
-		[]string{"site", "group", "host", "port", "protocol", "tags"},
+		[]string{"site", "group", "host", "port", "protocol", "tags", "source", "family", "address"},
 	)
 }
 
@@ -305,7 +510,7 @@ func createCheckLatencyMetric() *prometheus.GaugeVec {
 			Name:      "host_check_latency_milliseconds",
 			Help:      "Latency of the host check in milliseconds",
 		},
-		[]string{"site", "group", "host", "port", "protocol", "tags"},
+		[]string{"site", "group", "host", "port", "protocol", "tags", "source", "family", "address"},
 	)
 }
 
@@ -317,7 +522,18 @@ func createLatencyHistogram() *prometheus.HistogramVec {
 			Help:      "Histogram of check latencies in seconds",
 			Buckets:   prometheus.DefBuckets,
 		},
-		[]string{"site", "group", "port", "protocol", "tags"},
+		[]string{"site", "group", "port", "protocol", "tags", "source", "family"},
+	)
+}
+
+func createCheckErrorsMetric() *prometheus.CounterVec {
+	return promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "host_check_errors_total",
+			Help:      "Number of failed host checks by error class",
+		},
+		[]string{"site", "group", "host", "port", "protocol", "tags", "source", "family", "address", "error_class"},
 	)
 }
 
@@ -331,3 +547,36 @@ func createCertExpiryMetric() *prometheus.GaugeVec {
 		[]string{"site", "group", "host", "port", "issuer"},
 	)
 }
+
+func createDomainExpiryMetric() *prometheus.GaugeVec {
+	return promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "domain_expiry_days",
+			Help:      "Days until domain registration expiration",
+		},
+		[]string{"site", "group", "host", "registrar"},
+	)
+}
+
+func createPerfDataMetric() *prometheus.GaugeVec {
+	return promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "plugin_perfdata",
+			Help:      "Performance data values reported by EXEC check plugins",
+		},
+		[]string{"site", "group", "host", "port", "protocol", "label", "uom"},
+	)
+}
+
+func createMetadataMetric() *prometheus.GaugeVec {
+	return promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "host_check_metadata",
+			Help:      "Numeric metadata reported by host checks, durations in seconds",
+		},
+		[]string{"site", "group", "host", "port", "protocol", "tags", "source", "family", "address", "key"},
+	)
+}
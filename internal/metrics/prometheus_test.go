@@ -0,0 +1,62 @@
+// Copyright (C) 2025 Jeff Rose
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package metrics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestUpdateMetadata(t *testing.T) {
+	p := &PrometheusMetrics{
+		metadata: prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "host_check_metadata"},
+			[]string{"site", "group", "host", "port", "protocol", "tags", "source", "family", "address", "key"}),
+		metadataKeys: make(map[string]map[string]bool),
+	}
+	labels := MetricLabels{Site: "dc1", Group: "mail", Host: "mx1", Port: "25", Protocol: "EMAIL", Source: "eth1"}
+	value := func(tags, key string) float64 {
+		return testutil.ToFloat64(p.metadata.WithLabelValues("dc1", "mail", "mx1", "25", "EMAIL", tags, "eth1", "", "", key))
+	}
+
+	p.updateMetadata(labels, []string{"prod"}, map[string]interface{}{
+		"delivery_time": 90 * time.Second,
+		"attempts":      2,
+		"password_hint": 7,
+		"alpn":          "h2",
+	})
+	if got := testutil.CollectAndCount(p.metadata); got != 2 {
+		t.Fatalf("exported %d series, want 2", got)
+	}
+	if got := value("prod", "delivery_time"); got != 90 {
+		t.Errorf("delivery_time = %g, want 90", got)
+	}
+
+	p.updateMetadata(labels, []string{"prod"}, map[string]interface{}{"attempts": 1})
+	if got := testutil.CollectAndCount(p.metadata); got != 1 {
+		t.Fatalf("after delivery_time disappeared: %d series, want 1", got)
+	}
+
+	p.updateMetadata(labels, []string{"canary"}, map[string]interface{}{"attempts": 3})
+	if got := testutil.CollectAndCount(p.metadata); got != 1 {
+		t.Fatalf("after tags changed: %d series, want 1", got)
+	}
+	if got := value("canary", "attempts"); got != 3 {
+		t.Errorf("attempts = %g, want 3", got)
+	}
+}
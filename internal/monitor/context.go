@@ -19,6 +19,7 @@ import (
 	"context"
 	"time"
 
+	"github.com/whiskeyjimbo/CheckMate/internal/checkers"
 	"github.com/whiskeyjimbo/CheckMate/internal/config"
 	"github.com/whiskeyjimbo/CheckMate/internal/metrics"
 	"github.com/whiskeyjimbo/CheckMate/internal/notifications"
@@ -44,10 +45,14 @@ type BaseContext struct {
 
 type CheckContext struct {
 	Error       error
+	ErrorClass  checkers.ErrorClass
 	Logger      *zap.SugaredLogger
 	Site        string
 	Group       string
 	Host        string
+	Port        string
+	Family      string
+	Address     string
 	CheckConfig config.CheckConfig
 	Tags        []string
 	Elapsed     time.Duration
@@ -60,10 +65,36 @@ type GroupStats struct {
 	SuccessfulChecks int
 	TotalHosts       int
 	AvgResponseTime  time.Duration
+	// Metadata averages the numeric metadata reported by successful hosts
+	// and holds other metadata that all reporting hosts agree on
+	Metadata map[string]interface{}
+	// Earliest expiry across hosts, zero when no host reported one
+	CertExpiry   time.Time
+	DomainExpiry time.Time
+	// Failures holds the check error of each failing host
+	Failures map[string]error
+	// ErrorClass is the most common class among the failures
+	ErrorClass checkers.ErrorClass
+	// ErrorClasses lists the distinct classes among the failures, sorted
+	ErrorClasses []string
 }
 
+// HostResult is the outcome of checking one host in a round, or one address
+// or address family of it.
 type HostResult struct {
-	Error        error
-	ResponseTime time.Duration
+	Host         string
+	Family       string
+	Address      string
 	Success      bool
+	ResponseTime time.Duration
+	Error        error
+	ErrorClass   checkers.ErrorClass
+	// Metadata is what the checker reported, such as the resolved addresses
+	// of a DNS check or the certificate of an HTTPS check
+	Metadata map[string]interface{}
+	// Tags combines the tags of the check with those of the host
+	Tags []string
+	// Port is the port the host was checked on, which differs from that of
+	// the check for hosts with a port of their own
+	Port string
 }
@@ -16,7 +16,12 @@
 package monitor
 
 import (
+	"fmt"
+	"net"
+	"reflect"
+	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/whiskeyjimbo/CheckMate/internal/checkers"
@@ -27,91 +32,331 @@ import (
 	"github.com/whiskeyjimbo/CheckMate/internal/tags"
 )
 
-func performHostChecks(mc MonitoringContext, checker checkers.Checker) map[string]metrics.HostResult {
-	hostResults := make(map[string]metrics.HostResult)
-	hosts := make([]string, 0, len(mc.Base.Group.Hosts))
-
+func performHostChecks(mc MonitoringContext, checker checkers.Checker) map[string]HostResult {
+	// Hosts are checked together per port, as a checker takes a single port
+	var ports []string
+	hostsByPort := make(map[string][]config.HostConfig)
 	for _, host := range mc.Base.Group.Hosts {
-		hosts = append(hosts, host.Host)
+		port := mc.Check.PortFor(host)
+		if _, ok := hostsByPort[port]; !ok {
+			ports = append(ports, port)
+		}
+		hostsByPort[port] = append(hostsByPort[port], host)
 	}
 
-	results := checker.Check(mc.Base.Ctx, hosts, mc.Check.Port)
+	resultsByPort := make([][]checkers.HostCheckResult, len(ports))
+	var wg sync.WaitGroup
+	wg.Add(len(ports))
+	for i, port := range ports {
+		go func(index int, port string) {
+			defer wg.Done()
+			hosts := make([]string, 0, len(hostsByPort[port]))
+			for _, host := range hostsByPort[port] {
+				hosts = append(hosts, host.Host)
+			}
+			resultsByPort[index] = checker.Check(mc.Base.Ctx, hosts, port)
+		}(i, port)
+	}
+	wg.Wait()
 
-	for _, result := range results {
-		hostResults[result.Host] = metrics.HostResult{
+	hostResults := make(map[string]HostResult)
+	for i, port := range ports {
+		hostTags := make(map[string][]string, len(hostsByPort[port]))
+		for _, host := range hostsByPort[port] {
+			hostTags[host.Host] = tags.Deduplicate(tags.MergeTags(mc.Base.Tags, host.Tags))
+		}
+
+		for _, result := range resultsByPort[i] {
+			hostResults[resultKey(result, port, mc.Check.Port)] = HostResult{
+				Host:         result.Host,
+				Port:         port,
+				Family:       result.Family,
+				Address:      result.Address,
+				Success:      result.Success,
+				ResponseTime: result.ResponseTime,
+				Error:        result.Error,
+				Metadata:     result.Metadata,
+				ErrorClass:   checkers.ErrorClassOf(result.Error),
+				Tags:         hostTags[result.Host],
+			}
+
+			logCheckResult(CheckContext{
+				Logger:      mc.Base.Logger,
+				Site:        mc.Base.Site,
+				Group:       mc.Base.Group.Name,
+				Host:        result.Host,
+				Port:        port,
+				Family:      result.Family,
+				Address:     result.Address,
+				CheckConfig: mc.Check,
+				Success:     result.Success,
+				Error:       result.Error,
+				ErrorClass:  checkers.ErrorClassOf(result.Error),
+				Elapsed:     result.ResponseTime,
+				Tags:        hostTags[result.Host],
+			})
+		}
+	}
+
+	return hostResults
+}
+
+// metricsResults converts the round's results for the metrics package.
+func metricsResults(results map[string]HostResult) map[string]metrics.HostResult {
+	converted := make(map[string]metrics.HostResult, len(results))
+	for key, result := range results {
+		converted[key] = metrics.HostResult{
+			Host:         result.Host,
+			Family:       result.Family,
+			Address:      result.Address,
 			Success:      result.Success,
 			ResponseTime: result.ResponseTime,
 			Error:        result.Error,
+			Metadata:     result.Metadata,
+			ErrorClass:   result.ErrorClass,
+			Tags:         result.Tags,
+			Port:         result.Port,
 		}
-
-		logCheckResult(CheckContext{
-			Logger:      mc.Base.Logger,
-			Site:        mc.Base.Site,
-			Group:       mc.Base.Group.Name,
-			Host:        result.Host,
-			CheckConfig: mc.Check,
-			Success:     result.Success,
-			Error:       result.Error,
-			Elapsed:     result.ResponseTime,
-			Tags:        mc.Base.Tags,
-		})
 	}
+	return converted
+}
 
-	return hostResults
+// resultKey names a result in the group, telling apart the sub-hosts of a
+// host checked once per address or address family. Hosts checked on a port
+// of their own are named host:port, so instances sharing an address differ.
+func resultKey(result checkers.HostCheckResult, port, checkPort string) string {
+	host := result.Host
+	if port != checkPort {
+		host = net.JoinHostPort(host, port)
+	}
+	switch {
+	case result.Address != "":
+		return fmt.Sprintf("%s [%s]", host, result.Address)
+	case result.Family != "":
+		return fmt.Sprintf("%s (%s)", host, result.Family)
+	default:
+		return host
+	}
 }
 
-func calculateGroupStats(results map[string]metrics.HostResult) GroupStats {
+func calculateGroupStats(results map[string]HostResult) GroupStats {
 	stats := GroupStats{
 		AllDown:    true,
 		TotalHosts: len(results),
+		Failures:   make(map[string]error),
 	}
 
 	var totalResponseTime time.Duration
-	for _, result := range results {
+	for host, result := range results {
 		if result.Success {
 			stats.AllDown = false
 			stats.SuccessfulChecks++
 			totalResponseTime += result.ResponseTime
 		} else {
 			stats.AnyDown = true
+			if result.Error != nil {
+				stats.Failures[host] = result.Error
+			}
 		}
 	}
+	stats.ErrorClass = commonErrorClass(results)
+	stats.ErrorClasses = errorClasses(results)
 
 	if stats.SuccessfulChecks > 0 {
 		stats.AvgResponseTime = totalResponseTime / time.Duration(stats.SuccessfulChecks)
 	}
+	stats.Metadata = averageMetadata(results)
+	for key, value := range sharedMetadata(results) {
+		stats.Metadata[key] = value
+	}
+	stats.CertExpiry, stats.DomainExpiry = earliestExpiry(results)
 
 	return stats
 }
 
+// commonErrorClass returns the error class shared by most failing hosts,
+// the alphabetically first on a tie, or empty when nothing failed.
+func commonErrorClass(results map[string]HostResult) checkers.ErrorClass {
+	counts := make(map[checkers.ErrorClass]int)
+	for _, result := range results {
+		if !result.Success && result.ErrorClass != "" {
+			counts[result.ErrorClass]++
+		}
+	}
+
+	var common checkers.ErrorClass
+	for class, count := range counts {
+		if count > counts[common] || (count == counts[common] && class < common) {
+			common = class
+		}
+	}
+	return common
+}
+
+// errorClasses returns the distinct error classes of the failing hosts, so
+// a rule can match a class even when other failures outnumber it.
+func errorClasses(results map[string]HostResult) []string {
+	seen := make(map[checkers.ErrorClass]bool)
+	var classes []string
+	for _, result := range results {
+		if !result.Success && result.ErrorClass != "" && !seen[result.ErrorClass] {
+			seen[result.ErrorClass] = true
+			classes = append(classes, string(result.ErrorClass))
+		}
+	}
+	sort.Strings(classes)
+	return classes
+}
+
+func earliestExpiry(results map[string]HostResult) (time.Time, time.Time) {
+	var certExpiry, domainExpiry time.Time
+	for _, result := range results {
+		if info, ok := result.Metadata["cert_info"].(*checkers.CertInfo); ok {
+			if certExpiry.IsZero() || info.ExpiresAt.Before(certExpiry) {
+				certExpiry = info.ExpiresAt
+			}
+		}
+		if info, ok := result.Metadata["domain_info"].(*checkers.DomainInfo); ok && !info.ExpiresAt.IsZero() {
+			if domainExpiry.IsZero() || info.ExpiresAt.Before(domainExpiry) {
+				domainExpiry = info.ExpiresAt
+			}
+		}
+	}
+	return certExpiry, domainExpiry
+}
+
+func averageMetadata(results map[string]HostResult) map[string]interface{} {
+	sums := make(map[string]float64)
+	counts := make(map[string]int)
+	durations := make(map[string]bool)
+
+	for _, result := range results {
+		if !result.Success {
+			continue
+		}
+		for key, value := range result.Metadata {
+			number, ok := metadataNumber(value)
+			if !ok {
+				continue
+			}
+			if _, ok := value.(time.Duration); ok {
+				durations[key] = true
+			}
+			sums[key] += number
+			counts[key]++
+		}
+	}
+
+	averages := make(map[string]interface{}, len(sums))
+	for key, sum := range sums {
+		avg := sum / float64(counts[key])
+		if durations[key] {
+			averages[key] = time.Duration(avg)
+		} else {
+			averages[key] = avg
+		}
+	}
+	return averages
+}
+
+// sharedMetadata returns the non-numeric metadata, such as strings, flags and
+// address lists, that every host reporting it agrees on. Values that differ
+// between hosts are left out rather than picked arbitrarily.
+func sharedMetadata(results map[string]HostResult) map[string]interface{} {
+	shared := make(map[string]interface{})
+	conflicting := make(map[string]bool)
+
+	for _, result := range results {
+		for key, value := range result.Metadata {
+			if _, ok := metadataNumber(value); ok || conflicting[key] {
+				continue
+			}
+			if previous, ok := shared[key]; ok && !reflect.DeepEqual(previous, value) {
+				delete(shared, key)
+				conflicting[key] = true
+				continue
+			}
+			shared[key] = value
+		}
+	}
+	return shared
+}
+
+// metadataNumber returns a numeric metadata value as a float64, durations
+// in nanoseconds.
+func metadataNumber(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case time.Duration:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case float64:
+		return v, true
+	}
+	return 0, false
+}
+
 func processRules(
 	mc MonitoringContext,
-	stats GroupStats,
-	downtime time.Duration,
+	interval time.Duration,
+	downtimes map[string]time.Duration,
 	lastRuleEval map[string]time.Time,
 	ruleModeResolver *config.RuleModeResolver,
-	hostResults map[string]metrics.HostResult,
+	hostResults map[string]HostResult,
+	metadataKeys []string,
 ) {
-	failingHosts := collectFailingHosts(hostResults)
-
 	for _, rule := range mc.Rules {
-		if !tags.HasMatching(mc.Base.Tags, rule.Tags) {
+		// A rule only sees the hosts whose tags it matches, so a rule for
+		// primary hosts is not triggered by a backup going down
+		matching := matchingResults(hostResults, rule.Tags)
+		if len(matching) == 0 {
 			continue
 		}
 
-		evaluateAndProcessRule(mc, rule, stats, downtime, ruleModeResolver, failingHosts)
+		stats := calculateGroupStats(matching)
+		triggered := ruleModeResolver.ShouldTrigger(mc.Check, hostStatuses(matching))
+		downtimes[rule.Name] = updateDowntime(downtimes[rule.Name], interval, !triggered)
+
+		evaluateAndProcessRule(mc, rule, stats, downtimes[rule.Name], ruleModeResolver, matching, metadataKeys)
 		lastRuleEval[rule.Name] = time.Now()
 	}
 }
 
-func collectFailingHosts(hostResults map[string]metrics.HostResult) []string {
-	var failingHosts []string
-	for host, result := range hostResults {
-		if !result.Success {
-			failingHosts = append(failingHosts, host)
+// reportedKeys adds the metadata keys of hostResults to seen and returns
+// every key the check has reported so far.
+func reportedKeys(seen map[string]bool, hostResults map[string]HostResult) []string {
+	for _, result := range hostResults {
+		for key := range result.Metadata {
+			seen[key] = true
+		}
+	}
+	keys := make([]string, 0, len(seen))
+	for key := range seen {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+func matchingResults(hostResults map[string]HostResult, ruleTags []string) map[string]HostResult {
+	matching := make(map[string]HostResult, len(hostResults))
+	for key, result := range hostResults {
+		if tags.HasMatching(result.Tags, ruleTags) {
+			matching[key] = result
 		}
 	}
-	return failingHosts
+	return matching
+}
+
+func hostStatuses(hostResults map[string]HostResult) []config.HostStatus {
+	statuses := make([]config.HostStatus, 0, len(hostResults))
+	for _, result := range hostResults {
+		statuses = append(statuses, config.HostStatus{Host: result.Host, Down: !result.Success})
+	}
+	return statuses
 }
 
 func evaluateAndProcessRule(
@@ -120,37 +365,79 @@ func evaluateAndProcessRule(
 	stats GroupStats,
 	downtime time.Duration,
 	ruleModeResolver *config.RuleModeResolver,
-	failingHosts []string,
+	hostResults map[string]HostResult,
+	metadataKeys []string,
 ) {
 	params := rules.EvaluationParams{
-		Downtime:     downtime,
-		ResponseTime: stats.AvgResponseTime,
+		CertExpiryTime:   stats.CertExpiry,
+		DomainExpiryTime: stats.DomainExpiry,
+		Downtime:         downtime,
+		ResponseTime:     stats.AvgResponseTime,
+		Metadata:         stats.Metadata,
+		ErrorClass:       string(stats.ErrorClass),
+		ErrorClasses:     stats.ErrorClasses,
+		MetadataKeys:     metadataKeys,
 	}
 	ruleResult := rules.EvaluateRule(rule, params)
 	if !shouldSendNotification(ruleResult) {
 		return
 	}
 
-	effectiveMode := ruleModeResolver.GetEffectiveRuleMode(mc.Check)
-	sendNotifications(mc, rule, ruleResult, effectiveMode, stats, failingHosts)
+	sendNotifications(mc, rule, ruleResult, ruleModeResolver, stats, hostResults)
 }
 
 func shouldSendNotification(result rules.RuleResult) bool {
 	return result.Error != nil || result.Satisfied
 }
 
+// sendNotifications notifies failing hosts in any mode individually and
+// hosts in all mode together. When both modes share a check, the hosts in
+// all mode are only reported once all of them are down. Rule errors, cert
+// and domain rules, and rules satisfied while no host is down are not about
+// failing hosts and are always sent once for the group.
 func sendNotifications(
 	mc MonitoringContext,
 	rule rules.Rule,
 	ruleResult rules.RuleResult,
-	effectiveMode config.RuleMode,
+	ruleModeResolver *config.RuleModeResolver,
 	stats GroupStats,
-	failingHosts []string,
+	hostResults map[string]HostResult,
 ) {
-	if effectiveMode == config.RuleModeAny {
-		sendIndividualNotifications(mc, rule, ruleResult, effectiveMode, stats, failingHosts)
-	} else {
-		sendGroupNotification(mc, rule, ruleResult, effectiveMode, stats, failingHosts)
+	var anyFailing, allFailing, allTags, groupTags []string
+	var anyTotal, allTotal int
+	keys := make([]string, 0, len(hostResults))
+	for key := range hostResults {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		result := hostResults[key]
+		groupTags = append(groupTags, result.Tags...)
+		if ruleModeResolver.GetEffectiveRuleMode(mc.Check, result.Host) == config.RuleModeAny {
+			anyTotal++
+			if !result.Success {
+				anyFailing = append(anyFailing, key)
+			}
+			continue
+		}
+		allTotal++
+		allTags = append(allTags, result.Tags...)
+		if !result.Success {
+			allFailing = append(allFailing, key)
+		}
+	}
+
+	if ruleResult.Error != nil || rule.Type != rules.StandardRule || len(anyFailing)+len(allFailing) == 0 {
+		failing := append(anyFailing, allFailing...)
+		sort.Strings(failing)
+		sendGroupNotification(mc, rule, ruleResult, stats, failing, tags.Deduplicate(groupTags))
+		return
+	}
+
+	sendIndividualNotifications(mc, rule, ruleResult, stats, anyFailing, hostResults)
+	if allTotal > 0 && (anyTotal == 0 || len(allFailing) == allTotal) {
+		sendGroupNotification(mc, rule, ruleResult, stats, allFailing, tags.Deduplicate(allTags))
 	}
 }
 
@@ -158,12 +445,13 @@ func sendIndividualNotifications(
 	mc MonitoringContext,
 	rule rules.Rule,
 	ruleResult rules.RuleResult,
-	effectiveMode config.RuleMode,
 	stats GroupStats,
 	failingHosts []string,
+	hostResults map[string]HostResult,
 ) {
 	for _, failingHost := range failingHosts {
-		notification := createNotification(mc, rule, ruleResult, effectiveMode, stats, failingHost)
+		result := hostResults[failingHost]
+		notification := createNotification(mc, rule, ruleResult, config.RuleModeAny, stats, failingHost, result.Port, result.Tags, result.Metadata)
 		if err := notifications.SendRuleNotifications(mc.Base.Ctx, rule, notification, mc.Base.NotifierMap); err != nil {
 			mc.Base.Logger.Errorf("Failed to send notification for host %s: %v", failingHost, err)
 		}
@@ -174,11 +462,11 @@ func sendGroupNotification(
 	mc MonitoringContext,
 	rule rules.Rule,
 	ruleResult rules.RuleResult,
-	effectiveMode config.RuleMode,
 	stats GroupStats,
 	failingHosts []string,
+	groupTags []string,
 ) {
-	notification := createNotification(mc, rule, ruleResult, effectiveMode, stats, strings.Join(failingHosts, ","))
+	notification := createNotification(mc, rule, ruleResult, config.RuleModeAll, stats, strings.Join(failingHosts, ","), mc.Check.Port, groupTags, stats.Metadata)
 	if err := notifications.SendRuleNotifications(mc.Base.Ctx, rule, notification, mc.Base.NotifierMap); err != nil {
 		mc.Base.Logger.Errorf("Failed to send group notifications: %v", err)
 	}
@@ -191,17 +479,53 @@ func createNotification(
 	effectiveMode config.RuleMode,
 	stats GroupStats,
 	host string,
+	port string,
+	resultTags []string,
+	metadata map[string]interface{},
 ) notifications.Notification {
+	message := notifications.BuildMessage(rule, ruleResult, effectiveMode, stats.SuccessfulChecks, stats.TotalHosts)
+	if failureDetailProtocols[strings.ToUpper(mc.Check.Protocol)] {
+		if details := failureDetails(stats, host); details != "" {
+			message += ": " + details
+		}
+	}
+
 	return notifications.Notification{
-		Message:  notifications.BuildMessage(rule, ruleResult, effectiveMode, stats.SuccessfulChecks, stats.TotalHosts),
+		Message:  message,
 		Level:    notifications.GetLevel(ruleResult),
-		Tags:     mc.Base.Tags,
+		Tags:     resultTags,
 		Site:     mc.Base.Site,
 		Group:    mc.Base.Group.Name,
-		Port:     mc.Check.Port,
+		Port:     port,
 		Protocol: mc.Check.Protocol,
 		Host:     host,
+		Metadata: metadata,
+	}
+}
+
+// failureDetailProtocols lists the protocols whose check errors are findings
+// worth reading in the notification, such as the broken links of a crawl
+var failureDetailProtocols = map[string]bool{
+	"CRAWL": true,
+}
+
+// failureDetails returns the check errors of the notified hosts, which may
+// be a comma separated list in group mode.
+func failureDetails(stats GroupStats, host string) string {
+	hosts := strings.Split(host, ",")
+	var details []string
+	for _, h := range hosts {
+		err, ok := stats.Failures[h]
+		if !ok {
+			continue
+		}
+		if len(hosts) > 1 {
+			details = append(details, fmt.Sprintf("%s: %v", h, err))
+		} else {
+			details = append(details, err.Error())
+		}
 	}
+	return strings.Join(details, "; ")
 }
 
 func logCheckResult(ctx CheckContext) {
@@ -209,8 +533,12 @@ func logCheckResult(ctx CheckContext) {
 		"site", ctx.Site,
 		"group", ctx.Group,
 		"host", ctx.Host,
-		"port", ctx.CheckConfig.Port,
+		"port", ctx.Port,
 		"protocol", ctx.CheckConfig.Protocol,
+		"source", ctx.CheckConfig.Source.Label(),
+		"family", ctx.Family,
+		"address", ctx.Address,
+		"error_class", ctx.ErrorClass,
 		"latency_ms", ctx.Elapsed.Milliseconds(),
 		"success", ctx.Success,
 		"tags", ctx.Tags,
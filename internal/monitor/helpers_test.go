@@ -0,0 +1,161 @@
+// Copyright (C) 2025 Jeff Rose
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package monitor
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/whiskeyjimbo/CheckMate/internal/config"
+	"github.com/whiskeyjimbo/CheckMate/internal/notifications"
+	"github.com/whiskeyjimbo/CheckMate/internal/rules"
+	"go.uber.org/zap"
+)
+
+type recordingNotifier struct {
+	sent []notifications.Notification
+}
+
+func (n *recordingNotifier) SendNotification(_ context.Context, notification notifications.Notification) error {
+	n.sent = append(n.sent, notification)
+	return nil
+}
+
+func (n *recordingNotifier) Type() notifications.NotificationType { return "recording" }
+func (n *recordingNotifier) Initialize(context.Context) error     { return nil }
+func (n *recordingNotifier) Close() error                         { return nil }
+
+func TestSendNotificationsMixedRuleModes(t *testing.T) {
+	group := config.GroupConfig{
+		Name:     "api",
+		RuleMode: config.RuleModeAll,
+		Hosts: []config.HostConfig{
+			{Host: "api-1", RuleMode: config.RuleModeAny},
+			{Host: "api-2"},
+			{Host: "api-3"},
+		},
+	}
+	up := HostResult{Success: true}
+	down := HostResult{Error: errors.New("connection refused")}
+	results := func(states ...HostResult) map[string]HostResult {
+		hostResults := make(map[string]HostResult)
+		for i, state := range states {
+			state.Host = group.Hosts[i].Host
+			hostResults[state.Host] = state
+		}
+		return hostResults
+	}
+
+	tests := []struct {
+		name     string
+		rule     rules.Rule
+		result   rules.RuleResult
+		hosts    map[string]HostResult
+		notified []string
+	}{
+		{
+			name:     "any mode host down",
+			rule:     rules.Rule{Type: rules.StandardRule},
+			hosts:    results(down, up, down),
+			notified: []string{"api-1"},
+		},
+		{
+			name:     "all mode hosts down",
+			rule:     rules.Rule{Type: rules.StandardRule},
+			hosts:    results(up, down, down),
+			notified: []string{"api-2,api-3"},
+		},
+		{
+			name:     "satisfied with every host up",
+			rule:     rules.Rule{Type: rules.StandardRule},
+			hosts:    results(up, up, up),
+			notified: []string{""},
+		},
+		{
+			name:     "cert rule",
+			rule:     rules.Rule{Type: rules.CertRule},
+			hosts:    results(down, up, up),
+			notified: []string{"api-1"},
+		},
+		{
+			name:     "rule error",
+			rule:     rules.Rule{Type: rules.StandardRule},
+			result:   rules.RuleResult{Error: errors.New("bad condition")},
+			hosts:    results(down, down, up),
+			notified: []string{"api-1,api-2"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			notifier := &recordingNotifier{}
+			mc := MonitoringContext{
+				Base: BaseContext{
+					Ctx:         context.Background(),
+					Logger:      zap.NewNop().Sugar(),
+					Group:       group,
+					NotifierMap: map[string]notifications.Notifier{"recording": notifier},
+				},
+			}
+			result := tt.result
+			if result.Error == nil {
+				result.Satisfied = true
+			}
+
+			sendNotifications(mc, tt.rule, result, config.NewRuleModeResolver(group), calculateGroupStats(tt.hosts), tt.hosts)
+
+			var notified []string
+			for _, notification := range notifier.sent {
+				notified = append(notified, notification.Host)
+			}
+			if len(notified) != len(tt.notified) {
+				t.Fatalf("notified %q, want %q", notified, tt.notified)
+			}
+			for i := range notified {
+				if notified[i] != tt.notified[i] {
+					t.Errorf("notified %q, want %q", notified, tt.notified)
+				}
+			}
+		})
+	}
+}
+
+func TestCreateNotificationFailureDetails(t *testing.T) {
+	stats := GroupStats{
+		TotalHosts: 1,
+		Failures:   map[string]error{"web-1": errors.New("1 broken links: http://web-1/missing (404 Not Found)")},
+	}
+	rule := rules.Rule{Name: "down", Type: rules.StandardRule}
+	result := rules.RuleResult{Satisfied: true}
+
+	tests := []struct {
+		protocol    string
+		wantDetails bool
+	}{
+		{protocol: "CRAWL", wantDetails: true},
+		{protocol: "TCP"},
+		{protocol: "HTTP"},
+	}
+	for _, tt := range tests {
+		mc := MonitoringContext{Check: config.CheckConfig{Protocol: tt.protocol}}
+		notification := createNotification(mc, rule, result, config.RuleModeAll, stats, "web-1", "80", nil, nil)
+		if got := strings.Contains(notification.Message, "broken links"); got != tt.wantDetails {
+			t.Errorf("%s: message %q, details included %v, want %v", tt.protocol, notification.Message, got, tt.wantDetails)
+		}
+	}
+}
@@ -17,6 +17,7 @@ package monitor
 
 import (
 	"fmt"
+	"net"
 	"time"
 
 	"github.com/whiskeyjimbo/CheckMate/internal/checkers"
@@ -30,8 +31,9 @@ func WatchGroup(mc MonitoringContext) {
 		mc.Base.Logger.Fatal(err)
 	}
 
-	var downtime time.Duration
+	downtimes := make(map[string]time.Duration)
 	lastRuleEval := make(map[string]time.Time)
+	metadataKeys := make(map[string]bool)
 	ruleModeResolver := config.NewRuleModeResolver(mc.Base.Group)
 
 	for {
@@ -48,16 +50,14 @@ func WatchGroup(mc MonitoringContext) {
 				Group:       mc.Base.Group.Name,
 				Port:        mc.Check.Port,
 				Protocol:    mc.Check.Protocol,
+				Source:      mc.Check.Source.Label(),
 				Tags:        mc.Base.Tags,
-				HostResults: hostResults,
+				HostResults: metricsResults(hostResults),
 				HostsUp:     stats.SuccessfulChecks,
 				HostsTotal:  stats.TotalHosts,
 			})
 
-			shouldUpdateDowntime := ruleModeResolver.ShouldTrigger(stats.AnyDown, stats.AllDown, mc.Check)
-			downtime = updateDowntime(downtime, interval, !shouldUpdateDowntime)
-
-			processRules(mc, stats, downtime, lastRuleEval, ruleModeResolver, hostResults)
+			processRules(mc, interval, downtimes, lastRuleEval, ruleModeResolver, hostResults, reportedKeys(metadataKeys, hostResults))
 			waitForNextCheckInterval(interval, time.Since(checkStart))
 		}
 	}
@@ -75,13 +75,108 @@ func initializeChecker(mc MonitoringContext) (checkers.Checker, time.Duration, e
 		return nil, 0, fmt.Errorf("unsupported protocol %q. Supported protocols: %v", protocol, supported)
 	}
 
+	opts := checkerOptions(mc.Check)
+	opts["targets"] = checkTargets(mc)
+	if mc.Check.AddressFamily != config.FamilyBoth {
+		checker, err := newConfiguredChecker(mc.Check, interval, opts)
+		return checker, interval, err
+	}
+
+	// Each family gets its own checker so their results are reported apart
+	opts["address_family"] = checkers.FamilyIPv4
+	ipv4, err := newConfiguredChecker(mc.Check, interval, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	opts["address_family"] = checkers.FamilyIPv6
+	ipv6, err := newConfiguredChecker(mc.Check, interval, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	return checkers.NewDualStackChecker(ipv4, ipv6), interval, nil
+}
+
+// newConfiguredChecker creates and configures a checker, wrapping it to
+// retry failed hosts and to check every resolved address as configured.
+func newConfiguredChecker(check config.CheckConfig, interval time.Duration, opts checkers.Options) (checkers.Checker, error) {
+	protocol := checkers.Protocol(check.Protocol)
 	checker, err := checkers.NewChecker(protocol)
 	if err != nil {
-		return nil, 0, fmt.Errorf("failed to create checker: %w", err)
+		return nil, fmt.Errorf("failed to create checker: %w", err)
+	}
+
+	if check.Timeout != "" {
+		timeout, err := time.ParseDuration(check.Timeout)
+		if err != nil {
+			return nil, fmt.Errorf("invalid timeout: %w", err)
+		}
+		if err := checker.SetTimeout(timeout); err != nil {
+			return nil, fmt.Errorf("invalid timeout %s for %s: %w", timeout, protocol, err)
+		}
+	} else {
+		// Set the timeout to the interval (maybe i should update to interval-1 second), which will be validated by the checker, and min/max will be enforced
+		_ = checker.SetTimeout(interval)
 	}
 
-	// Set the timeout to the interval (maybe i should update to interval-1 second), which will be validated by the checker, and min/max will be enforced
-	_ = checker.SetTimeout(interval)
+	if configurable, ok := checker.(checkers.Configurable); ok {
+		if err := configurable.Configure(opts); err != nil {
+			return nil, fmt.Errorf("invalid %s options: %w", protocol, err)
+		}
+	}
 
-	return checker, interval, nil
+	if check.Retries > 0 {
+		var delay time.Duration
+		if check.RetryDelay != "" {
+			if delay, err = time.ParseDuration(check.RetryDelay); err != nil {
+				return nil, fmt.Errorf("invalid retry_delay: %w", err)
+			}
+		}
+		checker = checkers.NewRetryChecker(checker, check.Retries, delay)
+	}
+	if check.ResolveAll {
+		checker = checkers.NewFanOutChecker(checker, opts.String("address_family", ""))
+	}
+	return checker, nil
+}
+
+// checkTargets lists the host:port pairs the check runs against, for
+// checkers that must know them up front, such as HEARTBEAT registering the
+// tokens of its hosts.
+func checkTargets(mc MonitoringContext) []string {
+	targets := make([]string, 0, len(mc.Base.Group.Hosts))
+	for _, host := range mc.Base.Group.Hosts {
+		targets = append(targets, net.JoinHostPort(host.Host, mc.Check.PortFor(host)))
+	}
+	return targets
+}
+
+// checkerOptions merges the typed check settings that checkers consume with
+// the free-form options block.
+func checkerOptions(check config.CheckConfig) checkers.Options {
+	opts := make(checkers.Options, len(check.Options)+1)
+	for key, value := range check.Options {
+		opts[key] = value
+	}
+	if check.VerifyCert {
+		opts["verify_cert"] = true
+	}
+	if check.Expect != "" {
+		opts["expect"] = string(check.Expect)
+	}
+	if check.AddressFamily != "" && check.AddressFamily != config.FamilyBoth {
+		opts["address_family"] = string(check.AddressFamily)
+	}
+	if check.ResolveAll {
+		opts["resolve_all"] = true
+	}
+	if check.Proxy != "" {
+		opts["proxy"] = check.Proxy
+	}
+	if check.Source.SourceIP != "" {
+		opts["source_ip"] = check.Source.SourceIP
+	}
+	if check.Source.Interface != "" {
+		opts["interface"] = check.Source.Interface
+	}
+	return opts
 }
@@ -17,6 +17,7 @@ package notifications
 
 import (
 	"context"
+	"sort"
 
 	"go.uber.org/zap"
 )
@@ -41,6 +42,9 @@ func (n *LogNotifier) SendNotification(_ context.Context, notification Notificat
 		"protocol", notification.Protocol,
 		"tags", notification.Tags,
 	)
+	if len(notification.Metadata) > 0 {
+		logger = logger.With(metadataField(notification.Metadata))
+	}
 
 	switch notification.Level {
 	case ErrorLevel:
@@ -54,6 +58,22 @@ func (n *LogNotifier) SendNotification(_ context.Context, notification Notificat
 	return nil
 }
 
+// metadataField nests the metadata under a single field, in key order, so it
+// cannot clash with the fields above.
+func metadataField(metadata map[string]interface{}) zap.Field {
+	keys := make([]string, 0, len(metadata))
+	for key := range metadata {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	fields := make([]zap.Field, 0, len(keys))
+	for _, key := range keys {
+		fields = append(fields, zap.Any(key, metadata[key]))
+	}
+	return zap.Dict("metadata", fields...)
+}
+
 func (n *LogNotifier) Type() NotificationType {
 	return LogNotification
 }
@@ -49,6 +49,9 @@ type Notification struct {
 	Port     string
 	Protocol string
 	Tags     []string
+	// Metadata is the checker metadata of the notified host, or the group
+	// metadata for group notifications
+	Metadata map[string]interface{}
 }
 
 type Notifier interface {
@@ -21,13 +21,27 @@ import (
 )
 
 func evaluateCertRule(rule Rule, certExpiryTime time.Time) RuleResult {
-	daysUntilExpiry := time.Until(certExpiryTime).Hours() / 24
+	return evaluateExpiry(rule, "Certificate", certExpiryTime)
+}
+
+func evaluateDomainRule(rule Rule, domainExpiryTime time.Time) RuleResult {
+	return evaluateExpiry(rule, "Domain registration", domainExpiryTime)
+}
+
+// evaluateExpiry fires when fewer than MinDaysValidity days remain. A zero
+// expiry means the check did not report one, so there is nothing to evaluate.
+func evaluateExpiry(rule Rule, subject string, expiryTime time.Time) RuleResult {
+	if expiryTime.IsZero() {
+		return RuleResult{Satisfied: false}
+	}
+
+	daysUntilExpiry := time.Until(expiryTime).Hours() / 24
 
 	if daysUntilExpiry < float64(rule.MinDaysValidity) {
 		return RuleResult{
 			Satisfied: true,
-			Message: fmt.Sprintf("Certificate expires in %.1f days (threshold: %d days)",
-				daysUntilExpiry, rule.MinDaysValidity),
+			Message: fmt.Sprintf("%s expires in %.1f days (threshold: %d days)",
+				subject, daysUntilExpiry, rule.MinDaysValidity),
 		}
 	}
 
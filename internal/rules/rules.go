@@ -30,6 +30,7 @@ type RuleType string
 const (
 	StandardRule RuleType = "standard"
 	CertRule     RuleType = "cert"
+	DomainRule   RuleType = "domain"
 )
 
 type Rule struct {
@@ -53,9 +54,20 @@ var (
 )
 
 type EvaluationParams struct {
-	CertExpiryTime time.Time
-	Downtime       time.Duration
-	ResponseTime   time.Duration
+	CertExpiryTime   time.Time
+	DomainExpiryTime time.Time
+	Downtime         time.Duration
+	ResponseTime     time.Duration
+	// Metadata holds checker supplied values, keyed by their metadata name.
+	Metadata map[string]interface{}
+	// ErrorClass is the most common error class of the failing hosts, empty
+	// when all passed.
+	ErrorClass string
+	// ErrorClasses lists every error class among the failing hosts.
+	ErrorClasses []string
+	// MetadataKeys lists the metadata keys the check reported in earlier
+	// rounds, which conditions may use while no host reports them.
+	MetadataKeys []string
 }
 
 func (r Rule) Validate() error {
@@ -63,7 +75,7 @@ func (r Rule) Validate() error {
 		return errors.New("rule type must be specified")
 	}
 	switch r.Type {
-	case StandardRule, CertRule:
+	case StandardRule, CertRule, DomainRule:
 		return nil
 	default:
 		return fmt.Errorf("invalid rule type: %s", r.Type)
@@ -77,25 +89,31 @@ func EvaluateRule(rule Rule, params EvaluationParams) RuleResult {
 
 	switch rule.Type {
 	case StandardRule:
-		return evaluateStandardRule(rule, params.Downtime, params.ResponseTime)
+		return evaluateStandardRule(rule, params)
 	case CertRule:
 		return evaluateCertRule(rule, params.CertExpiryTime)
+	case DomainRule:
+		return evaluateDomainRule(rule, params.DomainExpiryTime)
 	}
 	return RuleResult{Error: fmt.Errorf("unsupported rule type: %s", rule.Type)}
 }
 
-func evaluateStandardRule(rule Rule, downtime, responseTime time.Duration) RuleResult {
+func evaluateStandardRule(rule Rule, params EvaluationParams) RuleResult {
 	if rule.Condition == "" {
 		return RuleResult{Error: ErrEmptyCondition}
 	}
 
-	env := map[string]interface{}{
-		"downtime":     timeDurationToSeconds(downtime),
-		"responseTime": timeDurationToSeconds(responseTime),
-	}
+	env := metadataVariables(params.Metadata)
+	env["downtime"] = timeDurationToSeconds(params.Downtime)
+	env["responseTime"] = timeDurationToSeconds(params.ResponseTime)
+	env["errorClass"] = params.ErrorClass
+	env["errorClasses"] = append([]string{}, params.ErrorClasses...)
 
 	condition := normalizeCondition(rule.Condition)
-	program, err := expr.Compile(condition, expr.Env(env))
+	if err := declareMissing(condition, env, params.MetadataKeys); err != nil {
+		return RuleResult{Error: fmt.Errorf("%w: %v", ErrInvalidSyntax, err)}
+	}
+	program, err := expr.Compile(condition, expr.Env(env), expr.AllowUndefinedVariables())
 	if err != nil {
 		return RuleResult{Error: fmt.Errorf("%w: %v", ErrInvalidSyntax, err)}
 	}
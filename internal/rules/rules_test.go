@@ -0,0 +1,124 @@
+// Copyright (C) 2025 Jeff Rose
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package rules
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestEvaluateStandardRuleMissingMetadata(t *testing.T) {
+	tests := []struct {
+		name      string
+		condition string
+		params    EvaluationParams
+		satisfied bool
+	}{
+		{
+			name:      "reported metadata",
+			condition: "deliveryTime > 120",
+			params:    EvaluationParams{Metadata: map[string]interface{}{"delivery_time": 3 * time.Minute}},
+			satisfied: true,
+		},
+		{
+			name:      "missing number compares as zero",
+			condition: "deliveryTime > 120",
+			satisfied: false,
+		},
+		{
+			name:      "missing number does not hide downtime",
+			condition: "deliveryTime > 120 || downtime > 0",
+			params:    EvaluationParams{Downtime: time.Minute},
+			satisfied: true,
+		},
+		{
+			name:      "missing list is nil",
+			condition: `"10.0.0.1" in ips`,
+			satisfied: false,
+		},
+		{
+			name:      "builtin functions are not shadowed",
+			condition: "len(ips) == 1",
+			params:    EvaluationParams{Metadata: map[string]interface{}{"ips": []string{"10.0.0.1"}}},
+			satisfied: true,
+		},
+		{
+			name:      "minority error class",
+			condition: `"tls_error" in errorClasses`,
+			params:    EvaluationParams{ErrorClass: "connection_refused", ErrorClasses: []string{"connection_refused", "tls_error"}},
+			satisfied: true,
+		},
+		{
+			name:      "no error classes",
+			condition: `"tls_error" in errorClasses`,
+			satisfied: false,
+		},
+		{
+			name:      "error class",
+			condition: `errorClass == "tls_error"`,
+			params:    EvaluationParams{ErrorClass: "tls_error"},
+			satisfied: true,
+		},
+		{
+			name:      "previously reported key",
+			condition: "queueDepth > 100 || downtime > 0",
+			params:    EvaluationParams{Downtime: time.Minute, MetadataKeys: []string{"queue_depth"}},
+			satisfied: true,
+		},
+		{
+			name:      "let variable",
+			condition: "let limit = 120; deliveryTime > limit",
+			satisfied: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := Rule{Name: tt.name, Type: StandardRule, Condition: tt.condition}
+			result := EvaluateRule(rule, tt.params)
+			if result.Error != nil {
+				t.Fatalf("unexpected error: %v", result.Error)
+			}
+			if result.Satisfied != tt.satisfied {
+				t.Errorf("satisfied = %v, want %v", result.Satisfied, tt.satisfied)
+			}
+		})
+	}
+}
+
+func TestEvaluateStandardRuleUnknownVariable(t *testing.T) {
+	tests := []struct {
+		condition string
+		params    EvaluationParams
+	}{
+		{condition: "downtme > 300"},
+		{condition: "responseTme > 2"},
+		{condition: `erorClass == "timeout"`},
+		{condition: "queueDepth > 100"},
+		{condition: "deliveryTime > 120 || downtme > 0", params: EvaluationParams{Metadata: map[string]interface{}{"delivery_time": time.Minute}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.condition, func(t *testing.T) {
+			rule := Rule{Name: "typo", Type: StandardRule, Condition: tt.condition}
+			result := EvaluateRule(rule, tt.params)
+			if !errors.Is(result.Error, ErrInvalidSyntax) {
+				t.Errorf("error = %v, want %v", result.Error, ErrInvalidSyntax)
+			}
+		})
+	}
+}
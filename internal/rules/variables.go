@@ -0,0 +1,179 @@
+// Copyright (C) 2025 Jeff Rose
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package rules
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/expr-lang/expr/ast"
+	"github.com/expr-lang/expr/parser"
+)
+
+// metadataVariables exposes checker metadata to rule conditions. Keys are
+// converted from snake_case to camelCase (delivery_time -> deliveryTime) and
+// durations become seconds, matching the downtime and responseTime variables.
+// Other values, such as strings, flags or the cert_info of HTTPS checks
+// (certInfo.IssuedBy), are available as they are.
+func metadataVariables(metadata map[string]interface{}) map[string]interface{} {
+	env := make(map[string]interface{}, len(metadata)+2)
+	for key, value := range metadata {
+		env[variableName(key)] = variableValue(value)
+	}
+	return env
+}
+
+// variableValue converts a metadata value to something conditions can
+// compare against literals, so resolved addresses can be matched with
+// `"10.0.0.1" in ips`.
+func variableValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case time.Duration:
+		return timeDurationToSeconds(v)
+	case time.Time:
+		return v
+	case []net.IP:
+		addresses := make([]string, len(v))
+		for i, ip := range v {
+			addresses[i] = ip.String()
+		}
+		return addresses
+	case fmt.Stringer:
+		return v.String()
+	}
+	return value
+}
+
+// variableName returns the rule variable name for a metadata key.
+func variableName(key string) string {
+	parts := strings.Split(key, "_")
+	for i := 1; i < len(parts); i++ {
+		if parts[i] != "" {
+			parts[i] = strings.ToUpper(parts[i][:1]) + parts[i][1:]
+		}
+	}
+	return strings.Join(parts, "")
+}
+
+// builtinMetadataKeys lists the metadata keys reported by the built-in
+// checkers. Conditions may use them while no host reports them.
+var builtinMetadataKeys = []string{
+	"age", "alpn", "attempts", "broken_count", "broken_links", "cert_info",
+	"containers", "containers_running", "containers_unhealthy",
+	"content_changed", "content_hash", "content_matched", "content_previous_hash", "cpus",
+	"delivery_time", "disk_used_percent", "domain_days_remaining",
+	"domain_info", "exit_code", "failed_step", "inode_used_percent", "ips",
+	"last_duration", "last_ping", "last_signal", "load1", "load5", "load15",
+	"matches", "memory_available_bytes", "memory_used_percent",
+	"missing_ports", "mounts", "open_count", "open_ports", "output",
+	"pages_checked", "path", "perfdata", "port_state", "process_count",
+	"protocol", "reason", "response_code", "restart_count", "samples_scraped",
+	"server", "since_last_ping", "sip_latency", "size_bytes", "status",
+	"steps", "swap_used_percent", "token", "unexpected_ports",
+}
+
+var builtinMetadataVariables = func() map[string]bool {
+	names := make(map[string]bool, len(builtinMetadataKeys))
+	for _, key := range builtinMetadataKeys {
+		names[variableName(key)] = true
+	}
+	return names
+}()
+
+// isMetadataVariable reports whether name is a metadata variable that may be
+// missing this round: a built-in key, an EXEC perf_ value, an HTTPFLOW step
+// time or a key the check reported before.
+func isMetadataVariable(name string, reported []string) bool {
+	if builtinMetadataVariables[name] {
+		return true
+	}
+	if strings.HasPrefix(name, "perf") || strings.HasPrefix(name, "step") && strings.HasSuffix(name, "Time") {
+		return true
+	}
+	for _, key := range reported {
+		if variableName(key) == name {
+			return true
+		}
+	}
+	return false
+}
+
+// declareMissing adds the metadata variables condition uses but env lacks,
+// such as metadata no host reported this round because all of them are
+// down. Operands of arithmetic and ordering comparisons default to 0, so
+// `deliveryTime > 120 || downtime > 0` still fires during an outage, and
+// other missing metadata variables are left undefined, which expr treats as
+// nil. Any other unknown identifier, such as a misspelled `downtme`, is an
+// error. Syntax errors are left for expr.Compile to report.
+func declareMissing(condition string, env map[string]interface{}, reported []string) error {
+	tree, err := parser.Parse(condition)
+	if err != nil {
+		return nil
+	}
+	v := &conditionVariables{
+		names:    make(map[string]bool),
+		numeric:  make(map[string]bool),
+		declared: make(map[string]bool),
+	}
+	ast.Walk(&tree.Node, v)
+
+	for name := range v.names {
+		if _, ok := env[name]; ok || v.declared[name] {
+			continue
+		}
+		if !isMetadataVariable(name, reported) {
+			return fmt.Errorf("unknown variable %s", name)
+		}
+		if v.numeric[name] {
+			env[name] = 0
+		}
+	}
+	return nil
+}
+
+// conditionVariables collects the identifiers of a condition, noting those
+// used as numbers and those declared with let.
+type conditionVariables struct {
+	names    map[string]bool
+	numeric  map[string]bool
+	declared map[string]bool
+}
+
+// Visit is called after the children of a node, so a call removes its
+// callee, which names a function rather than a variable.
+func (v *conditionVariables) Visit(node *ast.Node) {
+	switch n := (*node).(type) {
+	case *ast.IdentifierNode:
+		v.names[n.Value] = true
+	case *ast.CallNode:
+		if id, ok := n.Callee.(*ast.IdentifierNode); ok {
+			delete(v.names, id.Value)
+		}
+	case *ast.VariableDeclaratorNode:
+		v.declared[n.Name] = true
+	case *ast.BinaryNode:
+		switch n.Operator {
+		case "<", ">", "<=", ">=", "+", "-", "*", "/", "%", "**", "^":
+			for _, operand := range []ast.Node{n.Left, n.Right} {
+				if id, ok := operand.(*ast.IdentifierNode); ok {
+					v.numeric[id.Value] = true
+				}
+			}
+		}
+	}
+}
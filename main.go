@@ -40,10 +40,13 @@ func main() {
 
 	health.SetReady(false)
 
-	config, err := config.LoadConfiguration(os.Args)
+	config, err := config.LoadConfiguration(ctx, os.Args)
 	if err != nil {
 		logger.Fatal(err)
 	}
+	if config.PluginsDir != "" {
+		logger.With("protocols", config.Plugins).Info("Loaded checker plugins")
+	}
 
 	notifierMap := initializeNotifiers(ctx, logger, config.Notifications)
 	metrics.StartMetricsServer(logger)
@@ -62,29 +65,43 @@ func startMonitoring(
 	metrics *metrics.PrometheusMetrics,
 	notifierMap map[string]notifications.Notifier,
 ) {
+	watch := func(site string, group config.GroupConfig, check config.CheckConfig, tags []string) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			mc := monitor.MonitoringContext{
+				Base: monitor.BaseContext{
+					Ctx:         ctx,
+					Logger:      logger,
+					Site:        site,
+					Group:       group,
+					Tags:        tags,
+					NotifierMap: notifierMap,
+				},
+				Check:   check,
+				Metrics: metrics,
+				Rules:   cfg.Rules,
+			}
+			monitor.WatchGroup(mc)
+		}()
+	}
+
 	for _, site := range cfg.Sites {
 		for _, group := range site.Groups {
+			groupTags := tags.MergeTags(site.Tags, group.Tags)
 			for _, checkConfig := range group.Checks {
-				wg.Add(1)
-				combinedTags := tags.Deduplicate(append(append(site.Tags, group.Tags...), checkConfig.Tags...))
-
-				go func(site string, group config.GroupConfig, check config.CheckConfig, tags []string) {
-					defer wg.Done()
-					mc := monitor.MonitoringContext{
-						Base: monitor.BaseContext{
-							Ctx:         ctx,
-							Logger:      logger,
-							Site:        site,
-							Group:       group,
-							Tags:        tags,
-							NotifierMap: notifierMap,
-						},
-						Check:   check,
-						Metrics: metrics,
-						Rules:   cfg.Rules,
-					}
-					monitor.WatchGroup(mc)
-				}(site.Name, group, checkConfig, combinedTags)
+				watch(site.Name, group, checkConfig, tags.Deduplicate(tags.MergeTags(groupTags, checkConfig.Tags)))
+			}
+
+			// Host checks run against their own host only
+			for _, host := range group.Hosts {
+				hostGroup := group
+				hostGroup.Hosts = []config.HostConfig{host}
+				hostGroup.Checks = host.Checks
+				hostTags := tags.MergeTags(groupTags, host.Tags)
+				for _, checkConfig := range host.Checks {
+					watch(site.Name, hostGroup, checkConfig, tags.Deduplicate(tags.MergeTags(hostTags, checkConfig.Tags)))
+				}
 			}
 		}
 	}